@@ -3,15 +3,51 @@ package check
 import (
 	"go/ast"
 	"go/token"
-
-	goloader "code.google.com/p/go.tools/go/loader"
 )
 
 type AstNodeEvaler func(ast.Node) bool
 type AstWalker func(AstNodeEvaler)
 
+// AnyFinder is implemented by Finder, NodeFinder, PackageFinder and ProgramFinder
+type AnyFinder interface {
+	// RequiresTypes returns true if checks rely on complete type information;
+	// such finders are skipped for packages with errors
+	RequiresTypes() bool
+}
+
+// Finder finds checks in each file of a package;
+// walker traverses the whole file for each finder
 type Finder interface {
-	FindInAST(AstWalker, *goloader.PackageInfo, *ast.File, *token.FileSet) []Check
+	AnyFinder
+	FindInAST(AstWalker, *PackageInfo, *ast.File, *token.FileSet) []Check
+}
+
+// NodeFinder receives nodes of subscribed types during
+// a single traversal of each file shared by all node finders
+type NodeFinder interface {
+	AnyFinder
+
+	// NodeTypes returns types of nodes to receive
+	// e.g. []ast.Node{(*ast.CallExpr)(nil)}
+	NodeTypes() []ast.Node
+
+	// FindInNode returns found checks and false
+	// if children of the node should not be received
+	FindInNode(ast.Node, *PackageInfo, *ast.File, *token.FileSet) ([]Check, bool)
+}
+
+// PackageFinder finds checks once per package
+// (e.g. for rules about all package files)
+type PackageFinder interface {
+	AnyFinder
+	FindInPackage(*PackageInfo, *token.FileSet) []Check
+}
+
+// ProgramFinder finds checks once per loaded program
+// i.e. once per directory for a package and its tests
+type ProgramFinder interface {
+	AnyFinder
+	FindInProgram(*Program) []Check
 }
 
 type Check interface {
@@ -4,77 +4,121 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
-
-	goloader "code.google.com/p/go.tools/go/loader"
-	gotypes "code.google.com/p/go.tools/go/types"
+	"go/types"
+	"path"
 )
 
-type errorAssignmentsFinder struct{}
+type errorAssignmentsFinder struct {
+	opts errorAssignmentsOpts
+}
 
-func NewErrorAssignmentsFinder() errorAssignmentsFinder {
-	return errorAssignmentsFinder{}
+type errorAssignmentsOpts struct {
+	// Glob patterns matched against qualified function names
+	// e.g. fmt.Print*, (*bytes.Buffer).Write
+	IgnoreFuncs []string `json:"ignoreFuncs"`
 }
 
-func (c errorAssignmentsFinder) FindInAST(
-	walker AstWalker,
-	pkg *goloader.PackageInfo,
+func NewErrorAssignmentsFinder(options Options) (errorAssignmentsFinder, error) {
+	var opts errorAssignmentsOpts
+
+	err := options.Decode(&opts)
+	if err != nil {
+		return errorAssignmentsFinder{}, err
+	}
+
+	for _, pattern := range opts.IgnoreFuncs {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return errorAssignmentsFinder{}, fmt.Errorf("Invalid ignoreFuncs pattern '%s'", pattern)
+		}
+	}
+
+	return errorAssignmentsFinder{opts}, nil
+}
+
+func (c errorAssignmentsFinder) NodeTypes() []ast.Node {
+	return []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.ReturnStmt)(nil),
+		(*ast.CallExpr)(nil),
+		(*ast.GoStmt)(nil),
+		(*ast.DeferStmt)(nil),
+		(*ast.GenDecl)(nil),
+	}
+}
+
+func (c errorAssignmentsFinder) FindInNode(
+	n ast.Node,
+	pkg *PackageInfo,
 	file *ast.File,
 	fset *token.FileSet,
-) []Check {
+) ([]Check, bool) {
 	var checks []Check
 
-	walker(func(n ast.Node) bool {
-		// fmt.Printf("--> %#v\n", n)
-
-		switch x := n.(type) {
-		case *ast.AssignStmt:
-			for _, c := range NewAssignStmtErrorAssignment(pkg, fset, x) {
-				checks = append(checks, c)
+	switch x := n.(type) {
+	case *ast.AssignStmt:
+		for _, ea := range NewAssignStmtErrorAssignment(pkg, fset, x) {
+			if !c.ignores(ea.funcObj) {
+				checks = append(checks, ea)
 			}
+		}
+
+	case *ast.ReturnStmt:
+		// errors cannot be swallowed in return
 
-		case *ast.ReturnStmt:
-			// errors cannot be swallowed in return
+	case *ast.CallExpr:
+		check := NewCallExprErrorAssignment(pkg, fset, x)
 
-		case *ast.CallExpr:
-			checks = append(checks, NewCallExprErrorAssignment(pkg, fset, x))
+		if ea, ok := check.(errorAssignment); !ok || !c.ignores(ea.funcObj) {
+			checks = append(checks, check)
+		}
 
-		case *ast.GoStmt:
-			// todo
+	case *ast.GoStmt:
+		// todo
 
-		case *ast.DeferStmt:
-			// todo
+	case *ast.DeferStmt:
+		// todo
 
-		case *ast.GenDecl:
-			// todo (e.g. e = errors.New("msg"))
+	case *ast.GenDecl:
+		// todo (e.g. e = errors.New("msg"))
+	}
 
-		default:
+	// Nested calls are used as values (e.g. f(g()))
+	return checks, false
+}
+
+func (c errorAssignmentsFinder) RequiresTypes() bool { return true }
+
+func (c errorAssignmentsFinder) ignores(funcObj funcLike) bool {
+	name := funcName(funcObj)
+
+	for _, pattern := range c.opts.IgnoreFuncs {
+		if ok, _ := path.Match(pattern, name); ok {
 			return true
 		}
+	}
 
-		return false
-	})
-
-	return checks
+	return false
 }
 
 type funcLike interface {
 	String() string
-	Type() gotypes.Type
+	Type() types.Type
 }
 
 type errorAssignment struct {
-	pkg  *goloader.PackageInfo
+	pkg  *PackageInfo
 	fset *token.FileSet
 
 	// Some assignment variables might be unused-untyped (_);
-	// hence ast.Ident instead of gotypes.Var
+	// hence ast.Ident instead of types.Var
 	assignIdents []*ast.Ident
 
 	funcObj   funcLike
 	funcIdent *ast.Ident
 
 	// Always types since coming from function signature
-	funcReturnVars []*gotypes.Var
+	funcReturnVars []*types.Var
 }
 
 type noopErrorAssignment struct{}
@@ -82,9 +126,10 @@ type noopErrorAssignment struct{}
 // NewAssignStmtErrorAssignment constructs a check
 // for function calls used with assignment op.
 // e.g. a, b, := singleReturn(), singleReturn()
-//      a, b, c := multiReturn()
+//
+//	a, b, c := multiReturn()
 func NewAssignStmtErrorAssignment(
-	pkg *goloader.PackageInfo,
+	pkg *PackageInfo,
 	fset *token.FileSet,
 	stmt *ast.AssignStmt,
 ) []errorAssignment {
@@ -123,9 +168,10 @@ func NewAssignStmtErrorAssignment(
 // NewCallExprErrorAssignment constructs a check
 // for function calls used without assignment op.
 // e.g. singleReturn()
-//      multiReturn()
+//
+//	multiReturn()
 func NewCallExprErrorAssignment(
-	pkg *goloader.PackageInfo,
+	pkg *PackageInfo,
 	fset *token.FileSet,
 	expr *ast.CallExpr,
 ) Check {
@@ -148,9 +194,26 @@ func (c errorAssignment) Check() ([]Problem, error) {
 	var returnErrorVarIs []int
 	var problems []Problem
 
+	nilErrorVarIs := map[int]bool{}
+
+	// Facts are known for functions of packages loaded together
+	if funcObj, ok := c.funcObj.(*types.Func); ok {
+		if c.pkg.Facts.ImportObjectFact(funcObj, &noReturnFact{}) {
+			return problems, nil
+		}
+
+		var fact nilErrorsFact
+
+		if c.pkg.Facts.ImportObjectFact(funcObj, &fact) {
+			for _, i := range fact.Results {
+				nilErrorVarIs[i] = true
+			}
+		}
+	}
+
 	for i, var_ := range c.funcReturnVars {
-		if obj, ok := var_.Type().(*gotypes.Named); ok {
-			if obj.Obj().Name() == "error" {
+		if obj, ok := var_.Type().(*types.Named); ok {
+			if obj.Obj().Name() == "error" && !nilErrorVarIs[i] {
 				returnErrorVarIs = append(returnErrorVarIs, i)
 			}
 		}
@@ -180,10 +243,6 @@ func (c errorAssignment) Check() ([]Problem, error) {
 		}
 	}
 
-	if len(returnErrorVarIs) == 0 {
-		// fmt.Printf("No errors returned from %s", c.funcObj.String())
-	}
-
 	return problems, nil
 }
 
@@ -191,6 +250,22 @@ func (c noopErrorAssignment) Check() ([]Problem, error) {
 	return []Problem{}, nil
 }
 
+// funcName returns qualified function name
+// e.g. fmt.Printf, (*bytes.Buffer).Write, github.com/org/pkg.varFunc
+func funcName(funcObj funcLike) string {
+	switch x := funcObj.(type) {
+	case *types.Func:
+		return x.FullName()
+	case *types.Var:
+		if x.Pkg() != nil {
+			return x.Pkg().Path() + "." + x.Name()
+		}
+		return x.Name()
+	default:
+		return funcObj.String()
+	}
+}
+
 // extractAssignIdents extracts variable idents on lhs of assignment
 func extractAssignIdents(fset *token.FileSet, exprs []ast.Expr) []*ast.Ident {
 	var idents []*ast.Ident
@@ -229,10 +304,10 @@ func extractAssignIdents(fset *token.FileSet, exprs []ast.Expr) []*ast.Ident {
 
 // extractFunc extracts function defintion and return variables
 func extractFunc(
-	pkg *goloader.PackageInfo,
+	pkg *PackageInfo,
 	fset *token.FileSet,
 	expr *ast.CallExpr,
-) (funcLike, *ast.Ident, []*gotypes.Var) {
+) (funcLike, *ast.Ident, []*types.Var) {
 	var funcIdent *ast.Ident
 
 	switch x := expr.Fun.(type) {
@@ -273,33 +348,33 @@ func extractFunc(
 	var funcObj funcLike
 
 	switch x := pkg.Uses[funcIdent].(type) {
-	case *gotypes.Func:
+	case *types.Func:
 		funcObj = x
-	case *gotypes.Var: // closure? method?
+	case *types.Var: // closure? method?
 		funcObj = x
-	case *gotypes.TypeName: // huh?
+	case *types.TypeName: // huh?
 		return nil, nil, nil
-	case *gotypes.Builtin:
+	case *types.Builtin:
 		// Builtin funcs (e.g. append) do not have types
 		return nil, nil, nil
 	default:
 		panic(fmt.Sprintf("unknown funcIdent %#v", pkg.Uses[funcIdent]))
 	}
 
-	var funcSig *gotypes.Signature
+	var funcSig *types.Signature
 
 	switch x := funcObj.Type().(type) {
-	case *gotypes.Signature:
+	case *types.Signature:
 		funcSig = x
-	case *gotypes.Named:
-		funcSig = x.Underlying().(*gotypes.Signature)
-	case *gotypes.Slice: // funcObj is slice of callables
-		funcSig = x.Elem().Underlying().(*gotypes.Signature)
+	case *types.Named:
+		funcSig = x.Underlying().(*types.Signature)
+	case *types.Slice: // funcObj is slice of callables
+		funcSig = x.Elem().Underlying().(*types.Signature)
 	default:
 		panic(fmt.Sprintf("not types.Signature %#v", funcObj.Type()))
 	}
 
-	var funcReturnVars []*gotypes.Var
+	var funcReturnVars []*types.Var
 
 	sigReturnVars := funcSig.Results()
 	for i := 0; i < sigReturnVars.Len(); i++ {
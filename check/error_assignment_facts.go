@@ -0,0 +1,247 @@
+package check
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// nilErrorsFact is attached to functions that always return
+// nil errors (e.g. helpers satisfying an interface)
+type nilErrorsFact struct {
+	// Indexes of error results
+	Results []int
+}
+
+func (*nilErrorsFact) AFact() {}
+
+// noReturnFact is attached to functions that never return
+// (e.g. print a message and call os.Exit)
+type noReturnFact struct {
+	// Function that ends the program e.g. os.Exit
+	ExitFunc string
+}
+
+func (*noReturnFact) AFact() {}
+
+// noReturnFuncs never return; facts of standard library are not known
+var noReturnFuncs = map[string]bool{
+	"os.Exit":     true,
+	"log.Fatal":   true,
+	"log.Fatalf":  true,
+	"log.Fatalln": true,
+	"panic":       true,
+}
+
+func (c errorAssignmentsFinder) ComputeFacts(pkg *PackageInfo, fset *token.FileSet) error {
+	var funcDecls []*ast.FuncDecl
+
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				funcDecls = append(funcDecls, funcDecl)
+			}
+		}
+	}
+
+	// Functions might rely on facts of other functions
+	// in the same package hence repeat until nothing changes
+	for changed := true; changed; {
+		changed = false
+
+		for _, funcDecl := range funcDecls {
+			funcObj, ok := pkg.Defs[funcDecl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+
+			if !pkg.Facts.ImportObjectFact(funcObj, &noReturnFact{}) {
+				if exitFunc, found := c.exitFunc(pkg, funcDecl.Body); found {
+					err := pkg.Facts.ExportObjectFact(funcObj, &noReturnFact{exitFunc})
+					if err != nil {
+						return err
+					}
+					changed = true
+				}
+			}
+
+			if !pkg.Facts.ImportObjectFact(funcObj, &nilErrorsFact{}) {
+				if results, found := c.nilErrorResults(pkg, funcObj, funcDecl.Body); found {
+					err := pkg.Facts.ExportObjectFact(funcObj, &nilErrorsFact{results})
+					if err != nil {
+						return err
+					}
+					changed = true
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// exitFunc returns function that ends the program if body
+// does not return and unconditionally calls such function
+func (c errorAssignmentsFinder) exitFunc(pkg *PackageInfo, body *ast.BlockStmt) (string, bool) {
+	if len(returnStmts(body)) > 0 {
+		return "", false
+	}
+
+	for _, stmt := range body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+
+		callExpr, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+
+		switch x := calledObj(pkg, callExpr).(type) {
+		case *types.Builtin:
+			if noReturnFuncs[x.Name()] {
+				return x.Name(), true
+			}
+
+		case *types.Func:
+			if noReturnFuncs[x.FullName()] {
+				return x.FullName(), true
+			}
+
+			var fact noReturnFact
+
+			if pkg.Facts.ImportObjectFact(x, &fact) {
+				return fact.ExitFunc, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// nilErrorResults returns indexes of error results
+// if all return statements return nil errors
+func (c errorAssignmentsFinder) nilErrorResults(pkg *PackageInfo, funcObj *types.Func, body *ast.BlockStmt) ([]int, bool) {
+	sig := funcObj.Type().(*types.Signature)
+
+	results := errorResults(sig)
+	if len(results) == 0 {
+		return nil, false
+	}
+
+	stmts := returnStmts(body)
+	if len(stmts) == 0 {
+		return nil, false
+	}
+
+	for _, stmt := range stmts {
+		switch {
+		case len(stmt.Results) == 0:
+			// Named results might be assigned anywhere
+			return nil, false
+
+		case len(stmt.Results) == 1 && sig.Results().Len() > 1:
+			// e.g. return multiReturn()
+			if !c.returnsNilErrors(pkg, stmt.Results[0], results) {
+				return nil, false
+			}
+
+		default:
+			for _, i := range results {
+				if !c.isNilError(pkg, stmt.Results[i]) {
+					return nil, false
+				}
+			}
+		}
+	}
+
+	return results, true
+}
+
+// isNilError returns true for nil or a call returning only a nil error
+func (c errorAssignmentsFinder) isNilError(pkg *PackageInfo, expr ast.Expr) bool {
+	if ident, ok := ast.Unparen(expr).(*ast.Ident); ok {
+		_, isNil := pkg.Uses[ident].(*types.Nil)
+		return isNil
+	}
+
+	return c.returnsNilErrors(pkg, expr, []int{0})
+}
+
+func (c errorAssignmentsFinder) returnsNilErrors(pkg *PackageInfo, expr ast.Expr, results []int) bool {
+	callExpr, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+
+	funcObj, ok := calledObj(pkg, callExpr).(*types.Func)
+	if !ok {
+		return false
+	}
+
+	var fact nilErrorsFact
+
+	if !pkg.Facts.ImportObjectFact(funcObj, &fact) {
+		return false
+	}
+
+	nilResults := map[int]bool{}
+
+	for _, i := range fact.Results {
+		nilResults[i] = true
+	}
+
+	for _, i := range results {
+		if !nilResults[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// returnStmts finds return statements of the function
+// (excluding ones of nested function literals)
+func returnStmts(body *ast.BlockStmt) []*ast.ReturnStmt {
+	var stmts []*ast.ReturnStmt
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			stmts = append(stmts, x)
+		}
+		return true
+	})
+
+	return stmts
+}
+
+// calledObj returns called function (or builtin); nil if unknown
+func calledObj(pkg *PackageInfo, expr *ast.CallExpr) types.Object {
+	switch x := ast.Unparen(expr.Fun).(type) {
+	case *ast.Ident: // e.g. exit(...)
+		return pkg.Uses[x]
+	case *ast.SelectorExpr: // e.g. os.Exit(...)
+		return pkg.Uses[x.Sel]
+	default:
+		return nil
+	}
+}
+
+// errorResults returns indexes of results of type error
+func errorResults(sig *types.Signature) []int {
+	var results []int
+
+	for i := 0; i < sig.Results().Len(); i++ {
+		if obj, ok := sig.Results().At(i).Type().(*types.Named); ok {
+			if obj.Obj().Name() == "error" {
+				results = append(results, i)
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,234 @@
+package check
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Fact is information about an object (e.g. function never returns)
+// found while checking its package that is available to checks of
+// importing packages (similar to analysis.Fact). Facts are encoded with
+// encoding/gob hence should be pointers to structs with exported fields.
+type Fact interface {
+	AFact()
+}
+
+// FactFinder is implemented by finders that export facts
+// (in addition to implementing one of the finder interfaces)
+type FactFinder interface {
+	AnyFinder
+
+	// ComputeFacts exports facts about package objects via pkg.Facts;
+	// facts of imported packages have already been computed
+	ComputeFacts(*PackageInfo, *token.FileSet) error
+}
+
+type factKey struct {
+	pkgPath string
+	objPath string
+	typ     reflect.Type
+}
+
+// Facts holds facts for packages loaded together
+// (facts of other packages e.g. standard library are not known).
+// Only encoded facts are kept once facts of a package are computed
+// so that packages could be garbage collected once they are linted.
+type Facts struct {
+	// Packages which facts are not computed yet
+	pkgs map[string]*PackageInfo
+
+	// Closed once facts of a package are computed
+	computed map[string]chan struct{}
+
+	facts map[factKey][]byte
+	lock  sync.Mutex
+}
+
+func NewFacts() *Facts {
+	return &Facts{
+		pkgs:     map[string]*PackageInfo{},
+		computed: map[string]chan struct{}{},
+		facts:    map[factKey][]byte{},
+	}
+}
+
+// AddPackage makes package facts available once computed
+func (f *Facts) AddPackage(pkg *PackageInfo) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.pkgs[pkg.Pkg.Path()] = pkg
+	pkg.Facts = f
+}
+
+// Compute computes facts of the package and packages imported by its files
+// (including test files) in dependency order; compute is called at most once
+// for each package
+func (f *Facts) Compute(pkg *PackageInfo, fset *token.FileSet, compute func(*PackageInfo) []error) []error {
+	var errs []error
+
+	for _, file := range pkg.Files {
+		for _, spec := range file.Imports {
+			if imported, found := f.importedPkg(spec); found {
+				errs = append(errs, f.compute(imported, fset, compute)...)
+			}
+		}
+	}
+
+	return append(errs, f.compute(pkg, fset, compute)...)
+}
+
+// compute only follows imports of non-test files (they cannot form cycles)
+// and gives compute only non-test files since test files are not imported
+func (f *Facts) compute(pkg *PackageInfo, fset *token.FileSet, compute func(*PackageInfo) []error) []error {
+	f.lock.Lock()
+
+	computedCh, started := f.computed[pkg.Pkg.Path()]
+	if started {
+		f.lock.Unlock()
+		<-computedCh
+		return nil
+	}
+
+	computedCh = make(chan struct{})
+	f.computed[pkg.Pkg.Path()] = computedCh
+
+	f.lock.Unlock()
+
+	defer func() {
+		f.lock.Lock()
+		delete(f.pkgs, pkg.Pkg.Path())
+		f.lock.Unlock()
+
+		close(computedCh)
+	}()
+
+	nonTestPkg := *pkg
+	nonTestPkg.Files = nil
+
+	var errs []error
+
+	for _, file := range pkg.Files {
+		if strings.HasSuffix(fset.Position(file.Package).Filename, "_test.go") {
+			continue
+		}
+
+		nonTestPkg.Files = append(nonTestPkg.Files, file)
+
+		for _, spec := range file.Imports {
+			if imported, found := f.importedPkg(spec); found {
+				errs = append(errs, f.compute(imported, fset, compute)...)
+			}
+		}
+	}
+
+	return append(errs, compute(&nonTestPkg)...)
+}
+
+// ExportObjectFact attaches a fact to a package level object (or method)
+func (f *Facts) ExportObjectFact(obj types.Object, fact Fact) error {
+	key, err := f.key(obj, fact)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	err = gob.NewEncoder(&buf).Encode(fact)
+	if err != nil {
+		return fmt.Errorf("Encoding fact %T %#v", fact, err)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.facts[key] = buf.Bytes()
+
+	return nil
+}
+
+// ImportObjectFact populates fact (of the same type as exported)
+// and returns true if one was attached to the object
+func (f *Facts) ImportObjectFact(obj types.Object, fact Fact) bool {
+	if f == nil {
+		return false
+	}
+
+	key, err := f.key(obj, fact)
+	if err != nil {
+		return false
+	}
+
+	f.lock.Lock()
+	encoded, found := f.facts[key]
+	f.lock.Unlock()
+
+	if !found {
+		return false
+	}
+
+	return gob.NewDecoder(bytes.NewReader(encoded)).Decode(fact) == nil
+}
+
+func (f *Facts) key(obj types.Object, fact Fact) (factKey, error) {
+	objPath, err := objectPath(obj)
+	if err != nil {
+		return factKey{}, err
+	}
+
+	return factKey{obj.Pkg().Path(), objPath, reflect.TypeOf(fact)}, nil
+}
+
+// objectPath identifies package level objects and methods within
+// their package regardless of how package was loaded e.g. T.Close
+func objectPath(obj types.Object) (string, error) {
+	if obj.Pkg() == nil {
+		return "", fmt.Errorf("Expected object '%s' to belong to a package", obj.Name())
+	}
+
+	// Facts are attached to generic functions rather than their instances
+	if funcObj, ok := obj.(*types.Func); ok {
+		funcObj = funcObj.Origin()
+
+		if recv := funcObj.Type().(*types.Signature).Recv(); recv != nil {
+			recvType := recv.Type()
+			if ptr, ok := recvType.(*types.Pointer); ok {
+				recvType = ptr.Elem()
+			}
+
+			if named, ok := recvType.(*types.Named); ok {
+				return named.Obj().Name() + "." + funcObj.Name(), nil
+			}
+
+			return "", fmt.Errorf("Expected method '%s' to have a named receiver", funcObj.Name())
+		}
+	}
+
+	if obj.Parent() != obj.Pkg().Scope() {
+		return "", fmt.Errorf("Expected object '%s' to be declared at package level", obj.Name())
+	}
+
+	return obj.Name(), nil
+}
+
+func (f *Facts) importedPkg(spec *ast.ImportSpec) (*PackageInfo, bool) {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return nil, false
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	pkg, found := f.pkgs[path]
+
+	return pkg, found
+}
@@ -0,0 +1,83 @@
+package fix
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+func NewCommentRemoval(diff Diff, file *ast.File, comment *ast.Comment, fset *token.FileSet) fileRewrite {
+	fx := func() error {
+		var groups []*ast.CommentGroup
+
+		removed := map[*ast.CommentGroup]bool{}
+
+		for _, group := range file.Comments {
+			var list []*ast.Comment
+
+			for i, c := range group.List {
+				if c != comment {
+					list = append(list, c)
+					continue
+				}
+
+				// Preceding comments take places of the following ones
+				// so that they stay attached to the next node (e.g. doc comments)
+				for j := 0; j < i; j++ {
+					group.List[j].Slash = group.List[j+1].Slash
+				}
+			}
+
+			// Printer does not expect empty comment groups
+			if len(list) > 0 {
+				group.List = list
+				groups = append(groups, group)
+			} else {
+				removed[group] = true
+			}
+		}
+
+		file.Comments = groups
+
+		// Printer also prints comments referenced by nodes
+		// (e.g. doc comments) even if they are not in file comments
+		if len(removed) > 0 {
+			removeCommentGroups(file, removed)
+		}
+
+		return nil
+	}
+
+	return newFileRewrite(diff, fx, file, fset)
+}
+
+func removeCommentGroups(file *ast.File, removed map[*ast.CommentGroup]bool) {
+	clearGroup := func(group **ast.CommentGroup) {
+		if removed[*group] {
+			*group = nil
+		}
+	}
+
+	clearGroup(&file.Doc)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.FuncDecl:
+			clearGroup(&x.Doc)
+		case *ast.GenDecl:
+			clearGroup(&x.Doc)
+		case *ast.Field:
+			clearGroup(&x.Doc)
+			clearGroup(&x.Comment)
+		case *ast.ImportSpec:
+			clearGroup(&x.Doc)
+			clearGroup(&x.Comment)
+		case *ast.ValueSpec:
+			clearGroup(&x.Doc)
+			clearGroup(&x.Comment)
+		case *ast.TypeSpec:
+			clearGroup(&x.Doc)
+			clearGroup(&x.Comment)
+		}
+		return true
+	})
+}
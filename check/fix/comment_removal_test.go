@@ -0,0 +1,88 @@
+package fix_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check/fix"
+)
+
+func TestCommentRemoval(t *testing.T) {
+	examples := []struct {
+		Name     string
+		Source   string
+		Expected string
+	}{
+		{
+			Name:     "only comment in file is a doc comment",
+			Source:   "package d\n\n//lint:ignore errorAssignment stale reason\nfunc Z() int { return 1 }\n",
+			Expected: "package d\n\nfunc Z() int { return 1 }\n",
+		},
+		{
+			Name:     "doc comment of a type spec",
+			Source:   "package d\n\ntype (\n\t//lint:ignore errorAssignment stale reason\n\tT int\n)\n",
+			Expected: "package d\n\ntype (\n\tT int\n)\n",
+		},
+		{
+			Name:     "other doc comments stay",
+			Source:   "package d\n\n// Z returns one\n//lint:ignore errorAssignment stale reason\nfunc Z() int { return 1 }\n",
+			Expected: "package d\n\n// Z returns one\nfunc Z() int { return 1 }\n",
+		},
+		{
+			Name:     "trailing comment",
+			Source:   "package d\n\nfunc Z() int {\n\treturn 1 //lint:ignore errorAssignment stale reason\n}\n",
+			Expected: "package d\n\nfunc Z() int {\n\treturn 1\n}\n",
+		},
+	}
+
+	for _, ex := range examples {
+		t.Run(ex.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "main.go")
+
+			err := ioutil.WriteFile(path, []byte(ex.Source), 0600)
+			if err != nil {
+				t.Fatalf("WriteFile %v", err)
+			}
+
+			fset := token.NewFileSet()
+
+			file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("ParseFile %v", err)
+			}
+
+			comment := findComment(file, "//lint:ignore")
+			diff := fix.SimpleDiff{Name: "comment", Current: comment.Text, Desired: "(removed)"}
+
+			err = fix.NewCommentRemoval(diff, file, comment, fset).Fix()
+			if err != nil {
+				t.Fatalf("Fix %v", err)
+			}
+
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile %v", err)
+			}
+
+			if string(contents) != ex.Expected {
+				t.Fatalf("Expected %q but was %q", ex.Expected, string(contents))
+			}
+		})
+	}
+}
+
+func findComment(file *ast.File, prefix string) *ast.Comment {
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if strings.HasPrefix(c.Text, prefix) {
+				return c
+			}
+		}
+	}
+	panic("Expected to find comment with prefix " + prefix)
+}
@@ -7,23 +7,146 @@ import (
 	"go/printer"
 	"go/token"
 	"io/ioutil"
+	"sync"
 )
 
 type fileRewrite struct {
 	Diff
 
+	// Func modifies File; it is called at most once
+	// even if edits are requested before fixing
 	Func func() error
 
 	File *ast.File
 	Fset *token.FileSet
+
+	modified *modifiedOnce
+}
+
+// modifiedOnce records file contents around the only call to Func
+type modifiedOnce struct {
+	once sync.Once
+	err  error
+
+	original []byte // on disk
+	before   []byte // printed (includes changes of other fixes of the same file)
+	after    []byte // printed
+}
+
+func newFileRewrite(diff Diff, fx func() error, file *ast.File, fset *token.FileSet) fileRewrite {
+	return fileRewrite{
+		Diff: diff,
+		Func: fx,
+		File: file,
+		Fset: fset,
+
+		modified: &modifiedOnce{},
+	}
 }
 
 func (f fileRewrite) Fix() error {
-	err := f.Func()
+	err := f.modify()
+	if err != nil {
+		return err
+	}
+
+	path, err := f.path()
+	if err != nil {
+		return err
+	}
+
+	contents, err := f.print()
 	if err != nil {
 		return err
 	}
 
+	return ioutil.WriteFile(path, contents, 0)
+}
+
+// Edits returns a single edit (relative to the file on disk before
+// it was modified) replacing part of the file changed by this fix;
+// parsed file is only modified by the first call (or Fix)
+func (f fileRewrite) Edits() ([]TextEdit, error) {
+	if f.modified == nil {
+		return nil, fmt.Errorf("Fix '%s' cannot describe its changes", f.NameStr())
+	}
+
+	err := f.modify()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := f.path()
+	if err != nil {
+		return nil, err
+	}
+
+	m := f.modified
+
+	start, end, newEnd, changed := changedRange(m.before, m.after)
+	if !changed {
+		return nil, nil
+	}
+
+	newText := string(m.after[start:newEnd])
+
+	// Printed file might differ from the file on disk
+	// (e.g. not formatted or changed by other fixes)
+	if origStart, origEnd, printedEnd, changed := changedRange(m.original, m.before); changed {
+		shift := origEnd - printedEnd
+
+		switch {
+		case end <= origStart:
+			// changed before the difference
+		case start >= printedEnd:
+			start, end = start+shift, end+shift
+		default:
+			return nil, fmt.Errorf("Fix '%s' overlaps other changes of %s", f.NameStr(), path)
+		}
+	}
+
+	return []TextEdit{{File: path, Offset: start, End: end, NewText: newText}}, nil
+}
+
+// modify calls Func once recording file contents around it
+func (f fileRewrite) modify() error {
+	if f.modified == nil {
+		return f.Func()
+	}
+
+	m := f.modified
+
+	m.once.Do(func() {
+		path, err := f.path()
+		if err != nil {
+			m.err = err
+			return
+		}
+
+		m.original, err = ioutil.ReadFile(path)
+		if err != nil {
+			m.err = err
+			return
+		}
+
+		m.before, err = f.print()
+		if err != nil {
+			m.err = err
+			return
+		}
+
+		m.err = f.Func()
+		if m.err != nil {
+			return
+		}
+
+		m.after, m.err = f.print()
+	})
+
+	return m.err
+}
+
+func (f fileRewrite) print() ([]byte, error) {
 	// gofmt defaults
 	config := &printer.Config{
 		Mode:     printer.Mode(printer.UseSpaces | printer.TabIndent),
@@ -32,15 +155,40 @@ func (f fileRewrite) Fix() error {
 
 	var buf bytes.Buffer
 
-	err = config.Fprint(&buf, f.Fset, f.File)
+	err := config.Fprint(&buf, f.Fset, f.File)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	return buf.Bytes(), nil
+}
+
+func (f fileRewrite) path() (string, error) {
 	pos := f.Fset.Position(f.File.Package)
 	if !pos.IsValid() {
-		return fmt.Errorf("Invalid position: %v", pos.String())
+		return "", fmt.Errorf("Invalid position: %v", pos.String())
+	}
+
+	return pos.Filename, nil
+}
+
+// changedRange trims common prefix and suffix; old[start:end]
+// is replaced with new[start:newEnd]
+func changedRange(old, new []byte) (int, int, int, bool) {
+	if bytes.Equal(old, new) {
+		return 0, 0, 0, false
+	}
+
+	start := 0
+	for start < len(old) && start < len(new) && old[start] == new[start] {
+		start++
+	}
+
+	end, newEnd := len(old), len(new)
+	for end > start && newEnd > start && old[end-1] == new[newEnd-1] {
+		end--
+		newEnd--
 	}
 
-	return ioutil.WriteFile(pos.Filename, buf.Bytes(), 0)
+	return start, end, newEnd, true
 }
@@ -29,3 +29,10 @@ type Fix interface {
 	Diff
 	Fix() error
 }
+
+// EditsFix is implemented by fixes that could describe their
+// changes without applying them (e.g. to present them as edits)
+type EditsFix interface {
+	Fix
+	Edits() ([]TextEdit, error)
+}
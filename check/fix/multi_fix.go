@@ -0,0 +1,57 @@
+package fix
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+type multiFix struct {
+	Diff
+
+	Fixes []Fix
+}
+
+// NewPackageRenames renames package in all of its files with a single fix
+func NewPackageRenames(diff Diff, files []*ast.File, fset *token.FileSet) multiFix {
+	var fixes []Fix
+
+	for _, file := range files {
+		fixes = append(fixes, NewPackageRename(diff, file, fset))
+	}
+
+	return multiFix{Diff: diff, Fixes: fixes}
+}
+
+func (f multiFix) Fix() error {
+	for _, fix := range f.Fixes {
+		err := fix.Fix()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Edits returns edits of all fixes or an error
+// if one of the fixes cannot describe its changes
+func (f multiFix) Edits() ([]TextEdit, error) {
+	var edits []TextEdit
+
+	for _, fix := range f.Fixes {
+		editsFix, ok := fix.(EditsFix)
+		if !ok {
+			return nil, fmt.Errorf("Fix '%s' cannot describe its changes", fix.NameStr())
+		}
+
+		fixEdits, err := editsFix.Edits()
+		if err != nil {
+			return nil, err
+		}
+
+		edits = append(edits, fixEdits...)
+	}
+
+	return edits, nil
+}
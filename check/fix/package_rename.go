@@ -11,10 +11,5 @@ func NewPackageRename(diff Diff, file *ast.File, fset *token.FileSet) fileRewrit
 		return nil
 	}
 
-	return fileRewrite{
-		Diff: diff,
-		Func: fx,
-		File: file,
-		Fset: fset,
-	}
+	return newFileRewrite(diff, fx, file, fset)
 }
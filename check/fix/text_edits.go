@@ -0,0 +1,142 @@
+package fix
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// TextEdit replaces bytes [Offset, End) of a file with NewText
+type TextEdit struct {
+	File    string `json:"file"`
+	Offset  int    `json:"offset"`
+	End     int    `json:"end"`
+	NewText string `json:"newText"`
+}
+
+type textEdits struct {
+	Diff
+
+	TextEdits []TextEdit
+}
+
+func NewTextEdits(diff Diff, edits []TextEdit) textEdits {
+	return textEdits{Diff: diff, TextEdits: edits}
+}
+
+// CombineTextEdits merges all text edits into a single fix that goes first
+// since offsets of edits are only valid for unchanged files; duplicate edits
+// are applied once. Other fixes of the same files (that rewrite files from
+// their parsed contents) would undo text edits hence they fail instead.
+func CombineTextEdits(fixes []Fix) []Fix {
+	var combined textEdits
+	var others []Fix
+
+	seen := map[TextEdit]bool{}
+	editedFiles := map[string]bool{}
+
+	for _, f := range fixes {
+		edits, ok := f.(textEdits)
+		if !ok {
+			others = append(others, f)
+			continue
+		}
+
+		for _, edit := range edits.TextEdits {
+			if !seen[edit] {
+				seen[edit] = true
+				editedFiles[edit.File] = true
+				combined.TextEdits = append(combined.TextEdits, edit)
+			}
+		}
+	}
+
+	if len(combined.TextEdits) == 0 {
+		return others
+	}
+
+	for i, f := range others {
+		if path, found := editedFile(f, editedFiles); found {
+			others[i] = conflictingFix{f, path}
+		}
+	}
+
+	return append([]Fix{combined}, others...)
+}
+
+func editedFile(f Fix, editedFiles map[string]bool) (string, bool) {
+	editsFix, ok := f.(EditsFix)
+	if !ok {
+		return "", false
+	}
+
+	edits, err := editsFix.Edits()
+	if err != nil {
+		return "", false
+	}
+
+	for _, edit := range edits {
+		if editedFiles[edit.File] {
+			return edit.File, true
+		}
+	}
+
+	return "", false
+}
+
+type conflictingFix struct {
+	Diff
+
+	path string
+}
+
+func (f conflictingFix) Fix() error {
+	return fmt.Errorf("Fix '%s' conflicts with other fixes of %s (fix again to apply it)", f.NameStr(), f.path)
+}
+
+func (f textEdits) Fix() error {
+	editsByFile := map[string][]TextEdit{}
+
+	for _, edit := range f.TextEdits {
+		editsByFile[edit.File] = append(editsByFile[edit.File], edit)
+	}
+
+	for path, edits := range editsByFile {
+		err := f.fixFile(path, edits)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f textEdits) Edits() ([]TextEdit, error) { return f.TextEdits, nil }
+
+func (f textEdits) fixFile(path string, edits []TextEdit) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Applying edits from the end keeps offsets of earlier edits valid
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Offset > edits[j].Offset })
+
+	end := len(contents)
+
+	for _, edit := range edits {
+		if edit.Offset < 0 || edit.Offset > edit.End || edit.End > end {
+			return fmt.Errorf("Invalid or overlapping edit [%d, %d) of %s", edit.Offset, edit.End, path)
+		}
+
+		var updated []byte
+		updated = append(updated, contents[:edit.Offset]...)
+		updated = append(updated, edit.NewText...)
+		updated = append(updated, contents[edit.End:]...)
+
+		contents = updated
+		end = edit.Offset
+	}
+
+	return ioutil.WriteFile(path, contents, 0)
+}
@@ -0,0 +1,114 @@
+package fix_test
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check/fix"
+)
+
+func TestCombineTextEditsConflictingRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	err := ioutil.WriteFile(path, []byte("package other\n\nfunc A() {}\n"), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile %v", err)
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile %v", err)
+	}
+
+	edits := fix.NewTextEdits(
+		fix.SimpleDiff{Name: "func", Current: "A", Desired: "B"},
+		[]fix.TextEdit{{File: path, Offset: 20, End: 21, NewText: "B"}},
+	)
+	rename := fix.NewPackageRename(fix.SimpleDiff{Name: "package", Current: "other", Desired: "pkg"}, file, fset)
+
+	fixes := fix.CombineTextEdits([]fix.Fix{rename, edits})
+	if len(fixes) != 2 {
+		t.Fatalf("Expected 2 fixes but was %d", len(fixes))
+	}
+
+	// Text edits go first and succeed
+	err = fixes[0].Fix()
+	if err != nil {
+		t.Fatalf("Expected text edits to succeed but was %v", err)
+	}
+
+	// Rewrite of the same file would undo text edits
+	err = fixes[1].Fix()
+	if err == nil || !strings.Contains(err.Error(), "Fix 'package' conflicts with other fixes of "+path) {
+		t.Fatalf("Expected conflict error but was %v", err)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile %v", err)
+	}
+
+	expected := "package other\n\nfunc B() {}\n"
+
+	if string(contents) != expected {
+		t.Fatalf("Expected %q but was %q", expected, string(contents))
+	}
+}
+
+func TestCombineTextEditsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	editedPath := filepath.Join(dir, "edited.go")
+	renamedPath := filepath.Join(dir, "renamed.go")
+
+	for _, path := range []string{editedPath, renamedPath} {
+		err := ioutil.WriteFile(path, []byte("package other\n\nfunc A() {}\n"), 0600)
+		if err != nil {
+			t.Fatalf("WriteFile %v", err)
+		}
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, renamedPath, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile %v", err)
+	}
+
+	edit := fix.TextEdit{File: editedPath, Offset: 20, End: 21, NewText: "B"}
+	diff := fix.SimpleDiff{Name: "func", Current: "A", Desired: "B"}
+
+	fixes := fix.CombineTextEdits([]fix.Fix{
+		fix.NewPackageRename(fix.SimpleDiff{Name: "package", Current: "other", Desired: "pkg"}, file, fset),
+		fix.NewTextEdits(diff, []fix.TextEdit{edit}),
+		fix.NewTextEdits(diff, []fix.TextEdit{edit}), // duplicate is applied once
+	})
+
+	for _, f := range fixes {
+		err := f.Fix()
+		if err != nil {
+			t.Fatalf("Expected fix to succeed but was %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		editedPath:  "package other\n\nfunc B() {}\n",
+		renamedPath: "package pkg\n\nfunc A() {}\n",
+	}
+
+	for path, expectedContents := range expected {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile %v", err)
+		}
+
+		if string(contents) != expectedContents {
+			t.Fatalf("Expected %q but was %q", expectedContents, string(contents))
+		}
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"reflect"
 	"strings"
 
-	goloader "code.google.com/p/go.tools/go/loader"
-
 	"github.com/cppforlife/lint/check/fix"
 )
 
@@ -18,26 +16,37 @@ func NewGingkoSuiteTestFileFinder() gingkoSuiteTestFileFinder {
 	return gingkoSuiteTestFileFinder{}
 }
 
-func (c gingkoSuiteTestFileFinder) FindInAST(
-	walker AstWalker,
-	pkg *goloader.PackageInfo,
-	file *ast.File,
-	fset *token.FileSet,
-) []Check {
-	return []Check{NewGingkoSuiteTestFile(pkg, file, fset)}
+// FindInProgram looks at all packages since test files
+// of a directory might be split between pkg and pkg_test
+func (c gingkoSuiteTestFileFinder) FindInProgram(program *Program) []Check {
+	for _, pkg := range program.InitialPackages() {
+		for _, file := range pkg.Files {
+			check := NewGingkoSuiteTestFile(pkg, file, program.Fset)
+
+			// Suite test file is expected once per directory
+			if check.isGinkgoTestFile() {
+				return []Check{check}
+			}
+		}
+	}
+
+	return []Check{}
 }
 
+func (c gingkoSuiteTestFileFinder) RequiresTypes() bool { return false }
+
 type gingkoSuiteTestFile struct {
-	pkg  *goloader.PackageInfo
+	pkg  *PackageInfo
 	file *ast.File
 	fset *token.FileSet
 }
 
 // NewGingkoSuiteTestFile constructs a check
 // to make sure if ginkgo test library is used in *_test.go files,
-// *_suite_test.go files exists in directories with those files
+// *_suite_test.go files exists in directories with those files;
+// problem is reported for the given file
 func NewGingkoSuiteTestFile(
-	pkg *goloader.PackageInfo,
+	pkg *PackageInfo,
 	file *ast.File,
 	fset *token.FileSet,
 ) gingkoSuiteTestFile {
@@ -49,25 +58,31 @@ func NewGingkoSuiteTestFile(
 }
 
 func (c gingkoSuiteTestFile) Check() ([]Problem, error) {
-	pkgPos := c.fset.Position(c.file.Package)
-	fileName := filepath.Base(pkgPos.Filename)
-	dirPath := filepath.Dir(pkgPos.Filename)
+	if !c.isGinkgoTestFile() {
+		return []Problem{}, nil
+	}
 
-	isTestFile := strings.HasSuffix(fileName, "_test.go")
-	isSuiteTestFile := strings.HasSuffix(fileName, "suite_test.go")
+	dirPath := filepath.Dir(c.fset.Position(c.file.Package).Filename)
 
-	if isTestFile && !isSuiteTestFile && c.importsGinkgo() {
-		foundFileNames, err := c.suiteTestFileNames(dirPath)
-		if err != nil {
-			return []Problem{}, err
-		}
+	foundFileNames, err := c.suiteTestFileNames(dirPath)
+	if err != nil {
+		return []Problem{}, err
+	}
 
-		expectedFileName := filepath.Base(dirPath) + "_suite_test.go"
+	expectedFileName := filepath.Base(dirPath) + "_suite_test.go"
 
-		return c.compare(dirPath, expectedFileName, foundFileNames), nil
-	}
+	return c.compare(dirPath, expectedFileName, foundFileNames), nil
+}
+
+// isGinkgoTestFile determines if current file is a ginkgo test file
+// other than a suite test file
+func (c gingkoSuiteTestFile) isGinkgoTestFile() bool {
+	fileName := filepath.Base(c.fset.Position(c.file.Package).Filename)
+
+	isTestFile := strings.HasSuffix(fileName, "_test.go")
+	isSuiteTestFile := strings.HasSuffix(fileName, "suite_test.go")
 
-	return []Problem{}, nil
+	return isTestFile && !isSuiteTestFile && c.importsGinkgo()
 }
 
 func (c gingkoSuiteTestFile) compare(dirPath string, expectedFileName string, foundFileNames []string) []Problem {
@@ -0,0 +1,34 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Options hold check specific settings (e.g. from a configuration file)
+// that are decoded by each finder into its own options struct
+type Options json.RawMessage
+
+// Decode leaves opts untouched if there are no options;
+// unknown option names are considered to be an error
+func (o Options) Decode(opts interface{}) error {
+	if len(o) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(o))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(opts)
+	if err != nil {
+		return fmt.Errorf("Decoding options %s", err.Error())
+	}
+
+	return nil
+}
+
+func (o *Options) UnmarshalJSON(data []byte) error {
+	*o = append((*o)[0:0], data...)
+	return nil
+}
@@ -1,13 +1,10 @@
 package check
 
 import (
-	"go/ast"
 	"go/token"
 	"path/filepath"
 	"strings"
 
-	goloader "code.google.com/p/go.tools/go/loader"
-
 	"github.com/cppforlife/lint/check/fix"
 )
 
@@ -17,37 +14,34 @@ func NewPackageDirNameFinder() packageDirNameFinder {
 	return packageDirNameFinder{}
 }
 
-func (c packageDirNameFinder) FindInAST(
-	walker AstWalker,
-	pkg *goloader.PackageInfo,
-	file *ast.File,
-	fset *token.FileSet,
-) []Check {
-	return []Check{NewPackageDirName(pkg, file, fset)}
+func (c packageDirNameFinder) FindInPackage(pkg *PackageInfo, fset *token.FileSet) []Check {
+	return []Check{NewPackageDirName(pkg, fset)}
 }
 
+func (c packageDirNameFinder) RequiresTypes() bool { return false }
+
 type packageDirName struct {
-	pkg  *goloader.PackageInfo
-	file *ast.File
+	pkg  *PackageInfo
 	fset *token.FileSet
 }
 
 // NewPackageDirName constructs a check
 // to make sure directory name matches package name
-func NewPackageDirName(
-	pkg *goloader.PackageInfo,
-	file *ast.File,
-	fset *token.FileSet,
-) packageDirName {
+func NewPackageDirName(pkg *PackageInfo, fset *token.FileSet) packageDirName {
 	return packageDirName{
 		pkg:  pkg,
-		file: file,
 		fset: fset,
 	}
 }
 
 func (c packageDirName) Check() ([]Problem, error) {
-	pkgPos := c.fset.Position(c.file.Package)
+	if len(c.pkg.Files) == 0 {
+		return []Problem{}, nil
+	}
+
+	// Problem is reported once at the first file
+	// while fix renames package in all files
+	pkgPos := c.fset.Position(c.pkg.Files[0].Package)
 	dirName := filepath.Base(filepath.Dir(pkgPos.Filename))
 	pkgName := c.pkg.Pkg.Name()
 
@@ -70,34 +64,21 @@ func (c packageDirName) Check() ([]Problem, error) {
 			},
 		}
 
+		diff := fix.SimpleDiff{
+			Name:    "package",
+			Current: pkgName,
+			Desired: dirName,
+		}
+
 		if isTestPkgName {
 			problem.Text = "Test package name should match directory name with _text suffix"
-			problem.Fixes = []fix.Fix{
-				fix.NewPackageRename(
-					fix.SimpleDiff{
-						Name:    "package",
-						Current: pkgName,
-						Desired: dirName + "_test",
-					},
-					c.file,
-					c.fset,
-				),
-			}
+			diff.Desired = dirName + "_test"
 		} else {
 			problem.Text = "Package name should match directory name"
-			problem.Fixes = []fix.Fix{
-				fix.NewPackageRename(
-					fix.SimpleDiff{
-						Name:    "package",
-						Current: pkgName,
-						Desired: dirName,
-					},
-					c.file,
-					c.fset,
-				),
-			}
 		}
 
+		problem.Fixes = []fix.Fix{fix.NewPackageRenames(diff, c.pkg.Files, c.fset)}
+
 		problems = append(problems, problem)
 	}
 
@@ -0,0 +1,281 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cppforlife/lint/check/fix"
+)
+
+// PluginRequest is written to stdin of a plugin executable once per
+// directory i.e. once for a package and its tests; Files include
+// files of all packages
+type PluginRequest struct {
+	CheckID  string          `json:"checkID"`
+	Options  json.RawMessage `json:"options,omitempty"`
+	Dir      string          `json:"dir"`
+	Files    []string        `json:"files"`
+	Packages []PluginPackage `json:"packages"`
+}
+
+type PluginPackage struct {
+	Path    string   `json:"path"`
+	Name    string   `json:"name"`
+	Files   []string `json:"files"`
+	Imports []string `json:"imports"`
+}
+
+// PluginResponse is read from stdout of a plugin executable e.g.
+//
+//	{"problems": [{"text": "...", "file": "main.go", "line": 3, "column": 1,
+//	  "fixes": [{"name": "func", "current": "a", "desired": "b",
+//	    "edits": [{"file": "main.go", "offset": 20, "end": 21, "newText": "b"}]}]}]}
+//
+// Relative file paths are relative to the directory.
+type PluginResponse struct {
+	Problems []PluginProblem `json:"problems"`
+}
+
+type PluginProblem struct {
+	Text    string  `json:"text"`
+	File    string  `json:"file"`
+	Line    int     `json:"line"`
+	Column  int     `json:"column"`
+	Context Context `json:"context"`
+
+	Diffs []PluginDiff `json:"diffs"`
+	Fixes []PluginFix  `json:"fixes"`
+}
+
+type PluginDiff struct {
+	Name           string `json:"name"`
+	Current        string `json:"current"`
+	Desired        string `json:"desired"`
+	MissingCurrent bool   `json:"missingCurrent"`
+}
+
+type PluginFix struct {
+	PluginDiff
+	Edits []fix.TextEdit `json:"edits"`
+}
+
+type pluginFinder struct {
+	checkID string
+	command []string
+	timeout time.Duration
+	options Options
+}
+
+// NewPluginFinder returns a finder that runs command (with directory
+// of the program as a working directory) once for each program
+func NewPluginFinder(checkID string, command []string, timeout time.Duration, options Options) (pluginFinder, error) {
+	if len(command) == 0 {
+		return pluginFinder{}, fmt.Errorf("Expected plugin command to be specified")
+	}
+
+	if timeout <= 0 {
+		return pluginFinder{}, fmt.Errorf("Expected plugin timeout to be positive but was %s", timeout)
+	}
+
+	return pluginFinder{checkID, command, timeout, options}, nil
+}
+
+func (f pluginFinder) FindInProgram(program *Program) []Check {
+	if len(program.InitialPackages()) == 0 {
+		return nil
+	}
+
+	return []Check{pluginCheck{f, program}}
+}
+
+// Plugins parse files on their own
+func (f pluginFinder) RequiresTypes() bool { return false }
+
+type pluginCheck struct {
+	finder  pluginFinder
+	program *Program
+}
+
+func (c pluginCheck) Check() ([]Problem, error) {
+	reqBytes, err := json.Marshal(c.request())
+	if err != nil {
+		return nil, fmt.Errorf("Marshaling plugin request %#v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.finder.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.finder.command[0], c.finder.command[1:]...)
+	cmd.Dir = c.dir()
+	cmd.Stdin = bytes.NewReader(reqBytes)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Processes started by the plugin might keep its output open
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr == context.DeadlineExceeded {
+		return nil, fmt.Errorf("Running plugin '%s': timed out after %s",
+			strings.Join(c.finder.command, " "), c.finder.timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Running plugin '%s': %s: %s",
+			strings.Join(c.finder.command, " "), err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	var resp PluginResponse
+
+	err = json.Unmarshal(stdout.Bytes(), &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshaling plugin response %#v", err)
+	}
+
+	var problems []Problem
+
+	for _, pluginProblem := range resp.Problems {
+		problem, err := c.problem(pluginProblem)
+		if err != nil {
+			return nil, err
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// dir is the same for all packages of the program
+func (c pluginCheck) dir() string {
+	return c.program.InitialPackages()[0].Dir
+}
+
+func (c pluginCheck) request() PluginRequest {
+	req := PluginRequest{
+		CheckID: c.finder.checkID,
+		Options: json.RawMessage(c.finder.options),
+		Dir:     c.dir(),
+	}
+
+	seenFiles := map[string]bool{}
+
+	for _, pkg := range c.program.InitialPackages() {
+		pluginPkg := PluginPackage{
+			Path: pkg.Pkg.Path(),
+			Name: pkg.Pkg.Name(),
+		}
+
+		for _, file := range pkg.Files {
+			path := c.program.Fset.Position(file.Package).Filename
+			pluginPkg.Files = append(pluginPkg.Files, path)
+
+			if !seenFiles[path] {
+				seenFiles[path] = true
+				req.Files = append(req.Files, path)
+			}
+		}
+
+		for _, imported := range pkg.Pkg.Imports() {
+			pluginPkg.Imports = append(pluginPkg.Imports, imported.Path())
+		}
+
+		req.Packages = append(req.Packages, pluginPkg)
+	}
+
+	return req
+}
+
+func (c pluginCheck) problem(pluginProblem PluginProblem) (Problem, error) {
+	if len(pluginProblem.Text) == 0 {
+		return Problem{}, fmt.Errorf("Expected plugin problem to have text")
+	}
+
+	path := c.path(pluginProblem.File)
+
+	problem := Problem{
+		Text:     pluginProblem.Text,
+		Package:  c.pkg(path).Pkg,
+		Position: c.position(path, pluginProblem.Line, pluginProblem.Column),
+		Context:  pluginProblem.Context,
+	}
+
+	for _, diff := range pluginProblem.Diffs {
+		problem.Diffs = append(problem.Diffs, diff.simpleDiff())
+	}
+
+	for _, pluginFix := range pluginProblem.Fixes {
+		var edits []fix.TextEdit
+
+		for _, edit := range pluginFix.Edits {
+			edit.File = c.path(edit.File)
+			edits = append(edits, edit)
+		}
+
+		problem.Fixes = append(problem.Fixes, fix.NewTextEdits(pluginFix.simpleDiff(), edits))
+	}
+
+	return problem, nil
+}
+
+// pkg returns package that includes the file
+// (first package for files outside of the program)
+func (c pluginCheck) pkg(path string) *PackageInfo {
+	pkgs := c.program.InitialPackages()
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			if c.program.Fset.File(file.Package).Name() == path {
+				return pkg
+			}
+		}
+	}
+
+	return pkgs[0]
+}
+
+// position includes offset when file belongs to the program
+// (e.g. to find enclosing function for baseline entries)
+func (c pluginCheck) position(path string, line, column int) token.Position {
+	position := token.Position{Filename: path, Line: line, Column: column}
+
+	for _, pkg := range c.program.InitialPackages() {
+		for _, file := range pkg.Files {
+			tokenFile := c.program.Fset.File(file.Package)
+
+			if tokenFile.Name() == path && line > 0 && line <= tokenFile.LineCount() {
+				position.Offset = tokenFile.Offset(tokenFile.LineStart(line))
+				if column > 0 {
+					position.Offset += column - 1
+				}
+			}
+		}
+	}
+
+	return position
+}
+
+func (c pluginCheck) path(path string) string {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.dir(), path)
+}
+
+func (d PluginDiff) simpleDiff() fix.SimpleDiff {
+	return fix.SimpleDiff{
+		Name:           d.Name,
+		Current:        d.Current,
+		Desired:        d.Desired,
+		MissingCurrent: d.MissingCurrent,
+	}
+}
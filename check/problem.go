@@ -2,16 +2,22 @@ package check
 
 import (
 	"go/token"
-
-	gotypes "code.google.com/p/go.tools/go/types"
+	"go/types"
+	"sort"
 
 	"github.com/cppforlife/lint/check/fix"
 )
 
 type Problem struct {
+	// ID of the check that found the problem; set by the linter
+	CheckID string
+
+	// Severity of the check that found the problem; set by the linter
+	Severity Severity
+
 	Text string
 
-	Package  *gotypes.Package
+	Package  *types.Package
 	Position token.Position
 
 	Context Context
@@ -21,3 +27,16 @@ type Problem struct {
 }
 
 type Context map[string]string
+
+// Names returns context names in a stable order
+func (c Context) Names() []string {
+	var names []string
+
+	for name := range c {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
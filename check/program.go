@@ -0,0 +1,59 @@
+package check
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// PackageInfo holds type-checked package
+// together with its parsed files
+type PackageInfo struct {
+	Pkg   *types.Package
+	Dir   string
+	Files []*ast.File
+
+	types.Info
+
+	// Errors are only present when packages with errors
+	// are allowed to be loaded; type information is incomplete
+	Errors []PackageError
+
+	// Facts of this package and packages loaded together with it
+	Facts *Facts
+}
+
+func (p *PackageInfo) HasErrors() bool { return len(p.Errors) > 0 }
+
+// PackageError is an error found while loading or type-checking a package
+type PackageError struct {
+	Position token.Position
+	Msg      string
+}
+
+func (e PackageError) Error() string {
+	if !e.Position.IsValid() {
+		return e.Msg
+	}
+
+	return e.Position.String() + ": " + e.Msg
+}
+
+type Program struct {
+	Fset *token.FileSet
+
+	initialPkgs []*PackageInfo
+}
+
+func NewProgram(fset *token.FileSet, initialPkgs []*PackageInfo) *Program {
+	return &Program{
+		Fset:        fset,
+		initialPkgs: initialPkgs,
+	}
+}
+
+// InitialPackages returns packages that were requested to be loaded
+// (as opposed to packages that were loaded as dependencies)
+func (p *Program) InitialPackages() []*PackageInfo {
+	return p.initialPkgs
+}
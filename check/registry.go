@@ -0,0 +1,162 @@
+package check
+
+import (
+	"fmt"
+)
+
+// Definition describes a check that could be selected by its ID
+type Definition struct {
+	ID          string
+	Description string
+
+	EnabledByDefault bool
+
+	// Default severity of found problems
+	Severity Severity
+
+	NewFinder func(Options) (AnyFinder, error)
+}
+
+// Selection enables and then disables checks by their IDs
+type Selection struct {
+	Enable  []string
+	Disable []string
+}
+
+type Registry struct {
+	defs []Definition
+}
+
+// NewDefaultRegistry returns registry with all built-in checks
+func NewDefaultRegistry() *Registry {
+	registry := &Registry{}
+
+	registry.mustRegister(Definition{
+		ID:               "errorAssignment",
+		Description:      "Return values of type error should be assigned and used",
+		EnabledByDefault: true,
+		Severity:         SeverityError,
+		NewFinder:        func(opts Options) (AnyFinder, error) { return NewErrorAssignmentsFinder(opts) },
+	})
+
+	registry.mustRegister(Definition{
+		ID:               "testPackageSuffix",
+		Description:      "Test files should be in a corresponding _test package",
+		EnabledByDefault: true,
+		Severity:         SeverityWarning,
+		NewFinder:        func(Options) (AnyFinder, error) { return NewTestPackageSuffixFinder(), nil },
+	})
+
+	registry.mustRegister(Definition{
+		ID:               "packageDirName",
+		Description:      "Package name should match directory name",
+		EnabledByDefault: true,
+		Severity:         SeverityWarning,
+		NewFinder:        func(Options) (AnyFinder, error) { return NewPackageDirNameFinder(), nil },
+	})
+
+	registry.mustRegister(Definition{
+		ID:               "ginkgoSuiteTestFile",
+		Description:      "Ginkgo tests should have a suite test file named after directory",
+		EnabledByDefault: true,
+		Severity:         SeverityWarning,
+		NewFinder:        func(Options) (AnyFinder, error) { return NewGingkoSuiteTestFileFinder(), nil },
+	})
+
+	return registry
+}
+
+func (r *Registry) Register(def Definition) error {
+	if len(def.ID) == 0 {
+		return fmt.Errorf("Check ID must not be empty")
+	}
+
+	if _, found := r.Find(def.ID); found {
+		return fmt.Errorf("Check '%s' is already registered", def.ID)
+	}
+
+	if len(def.Severity) == 0 {
+		def.Severity = SeverityWarning
+	}
+
+	if _, err := ParseSeverity(string(def.Severity)); err != nil {
+		return fmt.Errorf("Check '%s': %s", def.ID, err.Error())
+	}
+
+	r.defs = append(r.defs, def)
+
+	return nil
+}
+
+func (r *Registry) mustRegister(def Definition) {
+	err := r.Register(def)
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+// Definitions returns all checks in the order they were registered
+func (r *Registry) Definitions() []Definition {
+	return r.defs
+}
+
+func (r *Registry) Find(id string) (Definition, bool) {
+	for _, def := range r.defs {
+		if def.ID == id {
+			return def, true
+		}
+	}
+
+	return Definition{}, false
+}
+
+// Select starts with checks enabled by default and applies selections in order
+// (later selections take precedence); "all" matches every check
+func (r *Registry) Select(selections ...Selection) ([]Definition, error) {
+	enabled := map[string]bool{}
+
+	for _, def := range r.defs {
+		enabled[def.ID] = def.EnabledByDefault
+	}
+
+	for _, selection := range selections {
+		err := r.setEnabled(enabled, selection.Enable, true)
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.setEnabled(enabled, selection.Disable, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var defs []Definition
+
+	for _, def := range r.defs {
+		if enabled[def.ID] {
+			defs = append(defs, def)
+		}
+	}
+
+	return defs, nil
+}
+
+func (r *Registry) setEnabled(enabled map[string]bool, ids []string, value bool) error {
+	for _, id := range ids {
+		if id == "all" {
+			for _, def := range r.defs {
+				enabled[def.ID] = value
+			}
+			continue
+		}
+
+		if _, found := r.Find(id); !found {
+			return fmt.Errorf("Unknown check '%s'", id)
+		}
+
+		enabled[id] = value
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Severity of a problem; checks have a default severity
+// that could be changed in configuration
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+func ParseSeverity(str string) (Severity, error) {
+	severity := Severity(str)
+
+	if _, found := severityRanks[severity]; !found {
+		return "", fmt.Errorf("Unknown severity '%s' (expected error, warning or info)", str)
+	}
+
+	return severity, nil
+}
+
+// AtLeast returns true if severity is same or more severe than other
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRanks[s] >= severityRanks[other]
+}
+
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var str string
+
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return fmt.Errorf("Expected severity to be a string")
+	}
+
+	*s, err = ParseSeverity(str)
+
+	return err
+}
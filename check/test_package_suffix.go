@@ -6,8 +6,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	goloader "code.google.com/p/go.tools/go/loader"
-
 	"github.com/cppforlife/lint/check/fix"
 )
 
@@ -19,15 +17,17 @@ func NewTestPackageSuffixFinder() testPackageSuffixFinder {
 
 func (c testPackageSuffixFinder) FindInAST(
 	walker AstWalker,
-	pkg *goloader.PackageInfo,
+	pkg *PackageInfo,
 	file *ast.File,
 	fset *token.FileSet,
 ) []Check {
 	return []Check{NewTestPackageSuffix(pkg, file, fset)}
 }
 
+func (c testPackageSuffixFinder) RequiresTypes() bool { return false }
+
 type testPackageSuffix struct {
-	pkg  *goloader.PackageInfo
+	pkg  *PackageInfo
 	file *ast.File
 	fset *token.FileSet
 }
@@ -36,7 +36,7 @@ type testPackageSuffix struct {
 // to make sure test files belong to a _test package
 // instead of the same package that is being tested
 func NewTestPackageSuffix(
-	pkg *goloader.PackageInfo,
+	pkg *PackageInfo,
 	file *ast.File,
 	fset *token.FileSet,
 ) testPackageSuffix {
@@ -2,16 +2,54 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
+	"github.com/cppforlife/lint/check"
 	"github.com/cppforlife/lint/linter"
 )
 
 var (
-	debugOpt = flag.Bool("debug", false, "show debugging information")
-	fixOpt   = flag.Bool("fix", false, "fix problems that can be fixed automatically")
+	debugOpt  = flag.Bool("debug", false, "show debugging information")
+	fixOpt    = flag.Bool("fix", false, "fix problems that can be fixed automatically")
+	formatOpt = flag.String("format", "text", "output format (text, json, ndjson with one JSON object per line, sarif, checkstyle, junit or line with path:line:col per problem)")
+	streamOpt = flag.Bool("stream", false, "show problems as soon as they are found instead of sorting them")
+	jOpt      = flag.Int("j", runtime.NumCPU(), "maximum number of directories per load, concurrent loads, packages linted and checks run per package")
+
+	formatTemplateOpt      = flag.String("format-template", "", "Go text/template used to render results (see --format-template-scope)")
+	formatTemplateFileOpt  = flag.String("format-template-file", "", "path to file with Go text/template used to render results")
+	formatTemplateScopeOpt = flag.String("format-template-scope", "problem", "render format template for each problem or once for the whole result")
+
+	absolutePathsOpt = flag.Bool("absolute-paths", false, "show absolute paths in line format instead of paths relative to the current directory")
+
+	includeOpt = flag.String("include", "", "comma-separated glob patterns of paths to include even if excluded")
+	excludeOpt = flag.String("exclude", "", "comma-separated glob patterns of paths to exclude in addition to .*, _*, testdata and vendor")
+
+	tagsOpt      = flag.String("tags", "", "comma-separated list of build tags")
+	goosOpt      = flag.String("goos", "", "target operating system (defaults to go env GOOS)")
+	goarchOpt    = flag.String("goarch", "", "target architecture (defaults to go env GOARCH)")
+	platformsOpt = flag.String("platforms", "", "comma-separated list of goos/goarch pairs to lint (e.g. linux/amd64,windows/amd64)")
+	cgoOpt       = flag.String("cgo", "", "enable (1) or disable (0) cgo (defaults to go env CGO_ENABLED)")
+
+	configOpt     = flag.String("config", "", "path to configuration file (defaults to "+linter.ConfigFileName+" found in the current directory or its parents)")
+	enableOpt     = flag.String("enable", "", "comma-separated list of check IDs to enable in addition to defaults (or all)")
+	disableOpt    = flag.String("disable", "", "comma-separated list of check IDs to disable (or all)")
+	listChecksOpt = flag.Bool("list-checks", false, "show available checks")
+
+	failOnOpt = flag.String("fail-on", "warning", "minimum severity of problems that fail linting (error, warning or info)")
+
+	allowErrorsOpt = flag.Bool("allow-errors", false, "lint packages with errors skipping checks that require type information")
+
+	reportUnusedIgnoresOpt = flag.Bool("report-unused-ignores", false, "report suppression comments that did not suppress any problems")
+
+	baselineOpt      = flag.String("baseline", "", "path to baseline file with problems that should not be reported")
+	writeBaselineOpt = flag.String("write-baseline", "", "path to baseline file to write with all found problems")
 )
 
 func main() {
@@ -19,25 +57,243 @@ func main() {
 
 	logger := buildLogger(*debugOpt)
 
-	ui := linter.NewPlainUI(os.Stdout, logger)
+	wd, err := os.Getwd()
+	if err != nil {
+		exitWithError(linter.NewPlainUI(os.Stdout, logger), err)
+	}
+
+	registry := check.NewDefaultRegistry()
+
+	ui, err := newFormatReporter(*formatOpt, wd, registry, logger)
+	if err != nil {
+		exitWithError(linter.NewPlainUI(os.Stdout, logger), err)
+	}
 
-	loader, err := linter.NewLoaderFromArgs(os.Getenv("GOPATH"), flag.Args(), logger)
+	config, err := loadConfig(*configOpt, wd)
 	if err != nil {
-		ui.DisplayError(err)
-		os.Exit(1)
+		exitWithError(ui, err)
 	}
 
-	reporter := ui
+	err = config.RegisterPlugins(registry)
+	if err != nil {
+		exitWithError(ui, err)
+	}
 
-	l := linter.NewLinter(reporter, logger)
+	err = config.Validate(registry)
+	if err != nil {
+		exitWithError(ui, err)
+	}
 
-	cli := linter.NewCLI(ui, loader, l, logger)
+	// Command line flags take precedence over configuration file
+	selection := check.Selection{
+		Enable:  splitIDs(*enableOpt),
+		Disable: splitIDs(*disableOpt),
+	}
+
+	finders, err := config.Finders(registry, wd, selection)
+	if err != nil {
+		exitWithError(ui, err)
+	}
+
+	if *listChecksOpt {
+		listChecks(registry, finders)
+		return
+	}
+
+	failOn, err := check.ParseSeverity(*failOnOpt)
+	if err != nil {
+		exitWithError(ui, err)
+	}
+
+	filter := linter.NewPathFilterFromStrs(wd, *includeOpt, *excludeOpt).With(config.Dir, config.Include, config.Exclude)
+
+	build, err := linter.NewBuildConfigFromStrs(*tagsOpt, *goosOpt, *goarchOpt, *platformsOpt, *cgoOpt)
+	if err != nil {
+		exitWithError(ui, err)
+	}
+
+	loader, err := linter.NewLoaderFromArgs(wd, flag.Args(), filter, build, *allowErrorsOpt, *jOpt, logger)
+	if err != nil {
+		exitWithError(ui, err)
+	}
+
+	var reporter linter.Reporter = ui
+	var cliUI linter.UI = ui
+
+	// By default output is sorted after all programs are linted
+	sortedReporter := linter.NewSortedReporter(ui, ui)
+	if !*streamOpt {
+		reporter = sortedReporter
+		cliUI = sortedReporter
+	}
+
+	var baseline, newBaseline *linter.Baseline
+
+	if len(*baselineOpt) > 0 {
+		baseline, err = linter.LoadBaseline(*baselineOpt)
+		if err != nil {
+			exitWithError(ui, err)
+		}
+	}
+
+	if len(*writeBaselineOpt) > 0 {
+		newBaseline = linter.NewBaseline(*writeBaselineOpt)
+	}
+
+	l := linter.NewLinter(
+		reporter, registry, config, selection,
+		*reportUnusedIgnoresOpt, baseline, newBaseline, *jOpt, logger,
+	)
+
+	cli := linter.NewCLI(cliUI, loader, l, *jOpt, logger)
 
 	err = cli.Run(*fixOpt)
+
+	sortedReporter.Flush()
+
+	if newBaseline != nil {
+		// Found problems are expected when writing a baseline
+		if _, ok := err.(linter.FoundProblemsError); ok {
+			err = nil
+		}
+
+		writeErr := newBaseline.Write()
+		if writeErr != nil {
+			exitWithError(ui, writeErr)
+		}
+	}
+
+	// Results that could not be presented fail linting regardless of found problems
+	flushErr := ui.Flush()
+	if flushErr != nil {
+		linter.NewPlainUI(os.Stderr, logger).DisplayError(flushErr)
+		os.Exit(linter.ExitCodeInternal)
+	}
+
+	// Errors were already displayed
+	os.Exit(linter.ExitCode(err, failOn))
+}
+
+func newFormatReporter(format, wd string, registry *check.Registry, logger *log.Logger) (linter.FormatReporter, error) {
+	if len(*formatTemplateOpt) > 0 || len(*formatTemplateFileOpt) > 0 {
+		return newTemplateReporter(format, wd, registry, logger)
+	}
+
+	switch format {
+	case "text":
+		return linter.NewPlainUI(os.Stdout, logger), nil
+	case "json":
+		return linter.NewJSONReporter(os.Stdout, false, logger), nil
+	case "ndjson":
+		return linter.NewJSONReporter(os.Stdout, true, logger), nil
+	case "sarif":
+		return linter.NewSARIFReporter(os.Stdout, wd, registry, logger), nil
+	case "checkstyle":
+		return linter.NewCheckstyleReporter(os.Stdout, linter.NewPlainUI(os.Stderr, logger), logger), nil
+	case "junit":
+		return linter.NewJUnitReporter(os.Stdout, logger), nil
+	case "line":
+		relDir := wd
+		if *absolutePathsOpt {
+			relDir = ""
+		}
+		return linter.NewLineReporter(os.Stdout, linter.NewPlainUI(os.Stderr, logger), relDir, logger), nil
+	default:
+		return nil, fmt.Errorf("Unknown format '%s'", format)
+	}
+}
+
+func newTemplateReporter(format, wd string, registry *check.Registry, logger *log.Logger) (linter.FormatReporter, error) {
+	if format != "text" {
+		return nil, fmt.Errorf("Expected format template to be used without format '%s'", format)
+	}
+
+	name, text := "format-template", *formatTemplateOpt
+
+	if len(*formatTemplateFileOpt) > 0 {
+		if len(text) > 0 {
+			return nil, fmt.Errorf("Expected only one of format template and format template file")
+		}
+
+		bytes, err := ioutil.ReadFile(*formatTemplateFileOpt)
+		if err != nil {
+			return nil, fmt.Errorf("Reading format template file %#v", err)
+		}
+
+		name, text = filepath.Base(*formatTemplateFileOpt), string(bytes)
+	}
+
+	var perProblem bool
+
+	switch *formatTemplateScopeOpt {
+	case "problem":
+		perProblem = true
+	case "result":
+		perProblem = false
+	default:
+		return nil, fmt.Errorf("Unknown format template scope '%s'", *formatTemplateScopeOpt)
+	}
+
+	tmpl, err := linter.ParseFormatTemplate(name, text, wd)
 	if err != nil {
-		ui.DisplayError(err)
-		os.Exit(1)
+		return nil, err
 	}
+
+	ui := linter.NewPlainUI(os.Stderr, logger)
+
+	return linter.NewTemplateReporter(os.Stdout, tmpl, perProblem, ui, registry, logger), nil
+}
+
+// exitWithError presents an error that prevented linting
+func exitWithError(ui linter.FormatReporter, err error) {
+	ui.DisplayError(err)
+
+	flushErr := ui.Flush()
+	if flushErr != nil {
+		log.New(os.Stderr, "", 0).Printf("[error] %s\n", flushErr.Error())
+	}
+
+	os.Exit(linter.ExitCodeInternal)
+}
+
+func loadConfig(path, wd string) (linter.Config, error) {
+	if len(path) > 0 {
+		return linter.LoadConfig(path)
+	}
+
+	return linter.FindConfig(wd)
+}
+
+// listChecks shows checks enabled for the current directory
+func listChecks(registry *check.Registry, finders []linter.CheckFinder) {
+	enabled := map[string]linter.CheckFinder{}
+
+	for _, finder := range finders {
+		enabled[finder.ID] = finder
+	}
+
+	for _, def := range registry.Definitions() {
+		state := "disabled"
+		if finder, found := enabled[def.ID]; found {
+			state = "enabled"
+			def = finder.Definition // includes configured severity
+		}
+
+		fmt.Printf("%-20s %-8s %-7s %s\n", def.ID, state, def.Severity, def.Description)
+	}
+}
+
+func splitIDs(str string) []string {
+	var ids []string
+
+	for _, id := range strings.Split(str, ",") {
+		id = strings.TrimSpace(id)
+		if len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
 }
 
 func buildLogger(debug bool) *log.Logger {
@@ -50,7 +306,7 @@ func buildLogger(debug bool) *log.Logger {
 
 		logDevice, err = os.Open(os.DevNull)
 		if err != nil {
-			os.Exit(1)
+			os.Exit(linter.ExitCodeInternal)
 		}
 	}
 
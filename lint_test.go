@@ -45,8 +45,15 @@ func TestAll(t *testing.T) {
 	}
 
 	expectedTestCaseNames = []string{
+		"baseline",
+
+		"config",
+		"config/integration",
+
 		"errorassignment",
 
+		"facts",
+
 		"ginkgosuitetestfile/invalid",
 		"ginkgosuitetestfile/missing",
 		"ginkgosuitetestfile/valid",
@@ -54,7 +61,13 @@ func TestAll(t *testing.T) {
 		"packagedirname/main",
 		"packagedirname/other",
 
+		"plugin",
+
+		"suppression",
+
 		"testpackagesuffix",
+
+		"typeerrors",
 	}
 
 	// Make sure all expected test cases are exercised
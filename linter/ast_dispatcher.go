@@ -0,0 +1,101 @@
+package linter
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// astDispatcher walks each file once and gives each node only to finders
+// subscribed to its type (similar to inspector.Preorder); unlike Preorder
+// each finder could skip children of a received node without affecting others
+type astDispatcher struct {
+	finders []CheckFinder
+
+	// Indexes of finders by subscribed node type
+	byType map[reflect.Type][]int
+}
+
+// newASTDispatcher expects finders to implement check.NodeFinder
+func newASTDispatcher(finders []CheckFinder) astDispatcher {
+	byType := map[reflect.Type][]int{}
+
+	for i, finder := range finders {
+		for _, node := range finder.AnyFinder.(check.NodeFinder).NodeTypes() {
+			typ := reflect.TypeOf(node)
+			byType[typ] = append(byType[typ], i)
+		}
+	}
+
+	return astDispatcher{finders, byType}
+}
+
+// Walk returns checks found by all finders; nodes for which
+// a finder panicked are skipped (including their children)
+func (d astDispatcher) Walk(pkg *check.PackageInfo, file *ast.File, fset *token.FileSet) ([]identifiedCheck, []error) {
+	var checks []identifiedCheck
+	var errs []error
+
+	if len(d.finders) == 0 {
+		return checks, errs
+	}
+
+	// Finders that do not want children of the node at the same stack depth
+	var stack [][]int
+	skipping := make([]bool, len(d.finders))
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			for _, i := range stack[len(stack)-1] {
+				skipping[i] = false
+			}
+			stack = stack[:len(stack)-1]
+			return false
+		}
+
+		var skipped []int
+
+		for _, i := range d.byType[reflect.TypeOf(n)] {
+			if skipping[i] {
+				continue
+			}
+
+			found, descend, err := d.findInNode(d.finders[i], n, pkg, file, fset)
+			checks = append(checks, found...)
+			errs = appendErr(errs, err)
+
+			if !descend {
+				skipping[i] = true
+				skipped = append(skipped, i)
+			}
+		}
+
+		stack = append(stack, skipped)
+
+		return true
+	})
+
+	return checks, errs
+}
+
+func (d astDispatcher) findInNode(
+	finder CheckFinder,
+	n ast.Node,
+	pkg *check.PackageInfo,
+	file *ast.File,
+	fset *token.FileSet,
+) (checks []identifiedCheck, descend bool, err error) {
+	position := fset.Position(n.Pos())
+
+	defer recoverCheck(finder.ID, position, &err)
+
+	found, descend := finder.AnyFinder.(check.NodeFinder).FindInNode(n, pkg, file, fset)
+
+	for _, c := range found {
+		checks = append(checks, identifiedCheck{finder.ID, finder.Severity, position, c})
+	}
+
+	return checks, descend, nil
+}
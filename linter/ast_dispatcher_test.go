@@ -0,0 +1,119 @@
+package linter_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// benchNodeFinder receives nodes of a single type during a shared walk
+type benchNodeFinder struct {
+	nodeType ast.Node
+}
+
+func (f benchNodeFinder) RequiresTypes() bool { return false }
+
+func (f benchNodeFinder) NodeTypes() []ast.Node { return []ast.Node{f.nodeType} }
+
+func (f benchNodeFinder) FindInNode(ast.Node, *check.PackageInfo, *ast.File, *token.FileSet) ([]check.Check, bool) {
+	return nil, true
+}
+
+// benchWalkFinder looks for nodes of a single type walking the file on its own
+type benchWalkFinder struct {
+	nodeType ast.Node
+}
+
+func (f benchWalkFinder) RequiresTypes() bool { return false }
+
+func (f benchWalkFinder) FindInAST(walker check.AstWalker, _ *check.PackageInfo, _ *ast.File, _ *token.FileSet) []check.Check {
+	typ := reflect.TypeOf(f.nodeType)
+
+	walker(func(n ast.Node) bool {
+		_ = reflect.TypeOf(n) == typ
+		return true
+	})
+
+	return nil
+}
+
+// Finders subscribe to different node types as checks usually do
+var benchNodeTypes = []ast.Node{
+	(*ast.CallExpr)(nil),
+	(*ast.AssignStmt)(nil),
+	(*ast.ReturnStmt)(nil),
+	(*ast.IfStmt)(nil),
+	(*ast.FuncDecl)(nil),
+	(*ast.GoStmt)(nil),
+	(*ast.DeferStmt)(nil),
+	(*ast.RangeStmt)(nil),
+}
+
+func BenchmarkLinterRunNodeFinders(b *testing.B) {
+	fset := token.NewFileSet()
+	program := check.NewProgram(fset, []*check.PackageInfo{parseTestPackage(b, fset, "bench", benchSource(200))})
+
+	for _, numFinders := range []int{1, 2, 4, 8, 16, 32} {
+		var nodeDefs, walkDefs []check.Definition
+
+		for i := 0; i < numFinders; i++ {
+			nodeType := benchNodeTypes[i%len(benchNodeTypes)]
+			nodeDefs = append(nodeDefs, finderDef(fmt.Sprintf("bench%d", i), check.SeverityWarning, benchNodeFinder{nodeType}))
+			walkDefs = append(walkDefs, finderDef(fmt.Sprintf("bench%d", i), check.SeverityWarning, benchWalkFinder{nodeType}))
+		}
+
+		// Cost stays flat since each file is walked once
+		b.Run(fmt.Sprintf("shared/%d", numFinders), func(b *testing.B) {
+			l := newTestLinter(b, nodeDefs...)
+
+			for n := 0; n < b.N; n++ {
+				_, err := l.Run(program)
+				if err != nil {
+					b.Fatalf("Run %v", err)
+				}
+			}
+		})
+
+		// Cost grows with each finder walking each file on its own
+		b.Run(fmt.Sprintf("separate/%d", numFinders), func(b *testing.B) {
+			l := newTestLinter(b, walkDefs...)
+
+			for n := 0; n < b.N; n++ {
+				_, err := l.Run(program)
+				if err != nil {
+					b.Fatalf("Run %v", err)
+				}
+			}
+		})
+	}
+}
+
+func benchSource(numFuncs int) string {
+	var src strings.Builder
+
+	src.WriteString("package bench\n")
+
+	for i := 0; i < numFuncs; i++ {
+		fmt.Fprintf(&src, `
+func f%d(vals []int) (int, error) {
+	sum := 0
+	for _, v := range vals {
+		if v > %d {
+			sum += g(v, h(v))
+		}
+	}
+	defer g(sum, 0)
+	return sum, nil
+}
+`, i, i)
+	}
+
+	src.WriteString("\nfunc g(a, b int) int { return a + b }\nfunc h(a int) int { return a }\n")
+
+	return src.String()
+}
@@ -0,0 +1,352 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// BaselineCheckID is used for baseline entries
+// that no longer match any problems
+const BaselineCheckID = "baseline"
+
+// Fingerprint identifies a problem regardless of its position
+// so that unrelated changes to a file do not affect it
+type Fingerprint struct {
+	CheckID  string `json:"checkID"`
+	Package  string `json:"package"`
+	Function string `json:"function,omitempty"`
+	Text     string `json:"text"`
+}
+
+// BaselineEntry is a problem found Count times within the same function
+type BaselineEntry struct {
+	Fingerprint
+	Count int `json:"count"`
+}
+
+// Baseline is a file with previously found problems e.g.
+//
+//	{"problems": [
+//	  {"checkID":"errorAssignment","package":"github.com/org/pkg","function":"(*T).Close","text":"...","count":2}
+//	]}
+//
+// Problems matching baseline entries are not reported.
+type Baseline struct {
+	path string
+
+	entries   []BaselineEntry
+	positions []token.Position
+
+	// Number of problems matched for each fingerprint
+	matched map[Fingerprint]int
+
+	// Problems found in this run
+	recorded map[Fingerprint]int
+
+	lock sync.Mutex
+}
+
+// NewBaseline returns an empty baseline that records found problems
+func NewBaseline(path string) *Baseline {
+	return &Baseline{
+		path:     path,
+		matched:  map[Fingerprint]int{},
+		recorded: map[Fingerprint]int{},
+	}
+}
+
+func LoadBaseline(path string) (*Baseline, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Reading baseline %#v", err)
+	}
+
+	baseline := NewBaseline(path)
+
+	// Entries are decoded one by one to remember their positions
+	dec := json.NewDecoder(bytes.NewReader(contents))
+
+	err = expectDelim(dec, '{')
+	if err != nil {
+		return nil, fmt.Errorf("Unmarshaling baseline %#v", err)
+	}
+
+	for dec.More() {
+		var key string
+
+		err = dec.Decode(&key)
+		if err != nil {
+			return nil, fmt.Errorf("Unmarshaling baseline %#v", err)
+		}
+
+		if key != "problems" {
+			return nil, fmt.Errorf("Unknown baseline field '%s'", key)
+		}
+
+		err = expectDelim(dec, '[')
+		if err != nil {
+			return nil, fmt.Errorf("Unmarshaling baseline %#v", err)
+		}
+
+		for dec.More() {
+			var entry BaselineEntry
+
+			err = dec.Decode(&entry)
+			if err != nil {
+				return nil, fmt.Errorf("Unmarshaling baseline %#v", err)
+			}
+
+			baseline.entries = append(baseline.entries, entry)
+			baseline.positions = append(baseline.positions, baselinePosition(path, contents, dec.InputOffset()))
+		}
+
+		err = expectDelim(dec, ']')
+		if err != nil {
+			return nil, fmt.Errorf("Unmarshaling baseline %#v", err)
+		}
+	}
+
+	return baseline, nil
+}
+
+// Filter returns problems not found in the baseline and number
+// of problems that were; each entry matches up to Count problems
+// across all linted programs
+func (b *Baseline) Filter(problems []check.Problem, fingerprints []Fingerprint) ([]check.Problem, int) {
+	var newProblems []check.Problem
+	var matched int
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	counts := b.counts()
+
+	for i, problem := range problems {
+		fp := fingerprints[i]
+
+		if b.matched[fp] < counts[fp] {
+			b.matched[fp]++
+			matched++
+		} else {
+			newProblems = append(newProblems, problem)
+		}
+	}
+
+	return newProblems, matched
+}
+
+// Record remembers problems so that they could be written
+func (b *Baseline) Record(fingerprints []Fingerprint) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, fp := range fingerprints {
+		b.recorded[fp]++
+	}
+}
+
+// StaleProblems returns problems for entries (of linted packages)
+// that matched fewer problems than they were recorded with
+func (b *Baseline) StaleProblems(pkgs map[string]*types.Package) []check.Problem {
+	var problems []check.Problem
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// Same fingerprint might be present in multiple entries
+	remaining := map[Fingerprint]int{}
+
+	for fp, count := range b.matched {
+		remaining[fp] = count
+	}
+
+	for i, entry := range b.entries {
+		pkg, found := pkgs[entry.Package]
+		if !found {
+			continue
+		}
+
+		matched := remaining[entry.Fingerprint]
+		if matched >= entry.Count {
+			remaining[entry.Fingerprint] -= entry.Count
+			continue
+		}
+
+		remaining[entry.Fingerprint] = 0
+
+		problems = append(problems, check.Problem{
+			CheckID:  BaselineCheckID,
+			Severity: baselineSeverity,
+			Text:     "Baseline entry should match a problem",
+			Package:  pkg,
+			Position: b.positions[i],
+			Context: check.Context{
+				"checkID":  entry.CheckID,
+				"function": entry.Function,
+				"text":     entry.Text,
+				"count":    fmt.Sprintf("%d (matched %d)", entry.Count, matched),
+			},
+		})
+	}
+
+	return problems
+}
+
+// Write saves recorded problems, one entry per line
+// so that changes to the baseline are easy to review
+func (b *Baseline) Write() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var entries []BaselineEntry
+
+	for fp, count := range b.recorded {
+		entries = append(entries, BaselineEntry{fp, count})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Function != b.Function {
+			return a.Function < b.Function
+		}
+		if a.CheckID != b.CheckID {
+			return a.CheckID < b.CheckID
+		}
+		return a.Text < b.Text
+	})
+
+	var buf bytes.Buffer
+
+	buf.WriteString("{\"problems\": [\n")
+
+	for i, entry := range entries {
+		entryBytes, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("Marshaling baseline entry %#v", err)
+		}
+
+		buf.WriteString("  ")
+		buf.Write(entryBytes)
+
+		if i < len(entries)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("]}\n")
+
+	err := ioutil.WriteFile(b.path, buf.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("Writing baseline %#v", err)
+	}
+
+	return nil
+}
+
+func (b *Baseline) counts() map[Fingerprint]int {
+	counts := map[Fingerprint]int{}
+
+	for _, entry := range b.entries {
+		counts[entry.Fingerprint] += entry.Count
+	}
+
+	return counts
+}
+
+// NewFingerprint uses enclosing function from files of linted program;
+// problems without a package have an empty package
+func NewFingerprint(problem check.Problem, files map[string]*ast.File, fset *token.FileSet) Fingerprint {
+	texts := []string{problem.Text}
+
+	for _, name := range problem.Context.Names() {
+		texts = append(texts, name+" = "+problem.Context[name])
+	}
+
+	fp := Fingerprint{
+		CheckID: problem.CheckID,
+		Text:    strings.Join(strings.Fields(strings.Join(texts, "; ")), " "),
+	}
+
+	if problem.Package != nil {
+		fp.Package = problem.Package.Path()
+	}
+
+	if file, found := files[problem.Position.Filename]; found {
+		fp.Function = enclosingFuncName(file, fset, problem.Position)
+	}
+
+	return fp
+}
+
+// enclosingFuncName returns name of a top level function containing position
+// e.g. main, T.String, (*T).Close
+func enclosingFuncName(file *ast.File, fset *token.FileSet, position token.Position) string {
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		start, end := fset.Position(funcDecl.Pos()), fset.Position(funcDecl.End())
+		if position.Offset < start.Offset || position.Offset >= end.Offset {
+			continue
+		}
+
+		if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+			return funcDecl.Name.Name
+		}
+
+		return receiverName(funcDecl.Recv.List[0].Type) + "." + funcDecl.Name.Name
+	}
+
+	return ""
+}
+
+func receiverName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return "(*" + receiverName(x.X) + ")"
+	case *ast.IndexExpr: // e.g. T[K]
+		return receiverName(x.X)
+	case *ast.IndexListExpr: // e.g. T[K, V]
+		return receiverName(x.X)
+	case *ast.Ident:
+		return x.Name
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("Expected '%s' but found '%v'", delim, token)
+	}
+
+	return nil
+}
+
+// baselinePosition returns position of the line on which entry ends
+func baselinePosition(path string, contents []byte, offset int64) token.Position {
+	line := 1 + bytes.Count(contents[:offset], []byte("\n"))
+
+	return token.Position{Filename: path, Offset: int(offset), Line: line, Column: 1}
+}
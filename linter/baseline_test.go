@@ -0,0 +1,73 @@
+package linter_test
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestNewFingerprint(t *testing.T) {
+	fset := token.NewFileSet()
+	pkg := parseTestPackage(t, fset, "a", "package a\n\nfunc main() {\n\tclose()\n}\n")
+	files := map[string]*ast.File{"/src/a/main.go": pkg.Files[0]}
+
+	// Position of close() call
+	position := fset.Position(pkg.Files[0].Decls[0].(*ast.FuncDecl).Body.List[0].Pos())
+
+	examples := []struct {
+		Name     string
+		Problem  check.Problem
+		Expected linter.Fingerprint
+	}{
+		{
+			Name: "problem in a function",
+			Problem: check.Problem{
+				CheckID:  "calls",
+				Text:     "Unexpected\n  call",
+				Package:  pkg.Pkg,
+				Position: position,
+				Context:  check.Context{"func": "close"},
+			},
+			Expected: linter.Fingerprint{
+				CheckID:  "calls",
+				Package:  "github.com/org/a",
+				Function: "main",
+				Text:     "Unexpected call; func = close",
+			},
+		},
+		{
+			Name: "problem without a package",
+			Problem: check.Problem{
+				CheckID:  "calls",
+				Text:     "Unexpected call",
+				Position: position,
+			},
+			Expected: linter.Fingerprint{
+				CheckID:  "calls",
+				Function: "main",
+				Text:     "Unexpected call",
+			},
+		},
+		{
+			Name: "problem outside of linted files",
+			Problem: check.Problem{
+				CheckID:  "calls",
+				Text:     "Unexpected call",
+				Position: token.Position{Filename: "/src/b/main.go", Line: 4, Column: 2},
+			},
+			Expected: linter.Fingerprint{CheckID: "calls", Text: "Unexpected call"},
+		},
+	}
+
+	for _, ex := range examples {
+		t.Run(ex.Name, func(t *testing.T) {
+			fp := linter.NewFingerprint(ex.Problem, files, fset)
+			if fp != ex.Expected {
+				t.Fatalf("Expected %#v but was %#v", ex.Expected, fp)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Platform struct {
+	GOOS   string
+	GOARCH string
+}
+
+func (p Platform) String() string {
+	if p.GOOS == "" && p.GOARCH == "" {
+		return "default platform"
+	}
+
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// Env returns environment variables to build for the platform;
+// empty values are left to be determined by the go tool
+func (p Platform) Env() []string {
+	var env []string
+
+	if p.GOOS != "" {
+		env = append(env, "GOOS="+p.GOOS)
+	}
+
+	if p.GOARCH != "" {
+		env = append(env, "GOARCH="+p.GOARCH)
+	}
+
+	return env
+}
+
+// BuildConfig determines which files are included when packages are loaded.
+// Packages are loaded and linted once for each platform.
+type BuildConfig struct {
+	Tags      []string
+	Platforms []Platform
+
+	// Either "1", "0" or empty to use go tool default
+	CgoEnabled string
+}
+
+// NewBuildConfigFromStrs builds configuration from command line options;
+// platforms (e.g. "linux/amd64,windows/amd64") take precedence over goos and goarch
+func NewBuildConfigFromStrs(tags, goos, goarch, platforms, cgo string) (BuildConfig, error) {
+	config := BuildConfig{
+		Tags:      splitPatterns(tags),
+		Platforms: []Platform{{GOOS: goos, GOARCH: goarch}},
+	}
+
+	if len(platforms) > 0 {
+		var err error
+
+		config.Platforms, err = parsePlatforms(platforms)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	switch cgo {
+	case "":
+	case "1", "true":
+		config.CgoEnabled = "1"
+	case "0", "false":
+		config.CgoEnabled = "0"
+	default:
+		return config, fmt.Errorf("Expected cgo to be either 1 or 0 but was '%s'", cgo)
+	}
+
+	return config, nil
+}
+
+func (c BuildConfig) Env(platform Platform) []string {
+	env := platform.Env()
+
+	if c.CgoEnabled != "" {
+		env = append(env, "CGO_ENABLED="+c.CgoEnabled)
+	}
+
+	return env
+}
+
+func (c BuildConfig) BuildFlags() []string {
+	if len(c.Tags) == 0 {
+		return nil
+	}
+
+	return []string{"-tags=" + strings.Join(c.Tags, ",")}
+}
+
+func parsePlatforms(str string) ([]Platform, error) {
+	var platforms []Platform
+
+	for _, pair := range splitPatterns(str) {
+		pieces := strings.Split(pair, "/")
+		if len(pieces) != 2 || pieces[0] == "" || pieces[1] == "" {
+			return nil, fmt.Errorf("Expected platform '%s' to be in goos/goarch format", pair)
+		}
+
+		platforms = append(platforms, Platform{GOOS: pieces[0], GOARCH: pieces[1]})
+	}
+
+	// Packages would not be linted at all otherwise
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("Expected at least one platform in '%s'", str)
+	}
+
+	return platforms, nil
+}
@@ -0,0 +1,84 @@
+package linter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestNewBuildConfigFromStrs(t *testing.T) {
+	examples := []struct {
+		tags, goos, goarch, platforms, cgo string
+
+		expected linter.BuildConfig
+		err      string
+	}{
+		{
+			expected: linter.BuildConfig{Platforms: []linter.Platform{{}}},
+		},
+		{
+			tags: "integration, e2e", goos: "windows", goarch: "arm64", cgo: "0",
+			expected: linter.BuildConfig{
+				Tags:       []string{"integration", "e2e"},
+				Platforms:  []linter.Platform{{GOOS: "windows", GOARCH: "arm64"}},
+				CgoEnabled: "0",
+			},
+		},
+		{
+			// Platforms take precedence over goos and goarch
+			goos: "windows", platforms: "linux/amd64, darwin/arm64", cgo: "true",
+			expected: linter.BuildConfig{
+				Platforms:  []linter.Platform{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "darwin", GOARCH: "arm64"}},
+				CgoEnabled: "1",
+			},
+		},
+		{cgo: "false", expected: linter.BuildConfig{Platforms: []linter.Platform{{}}, CgoEnabled: "0"}},
+
+		{platforms: "linux", err: "Expected platform 'linux' to be in goos/goarch format"},
+		{platforms: "linux/", err: "Expected platform 'linux/' to be in goos/goarch format"},
+		{platforms: "/amd64", err: "Expected platform '/amd64' to be in goos/goarch format"},
+		{platforms: "a/b/c", err: "Expected platform 'a/b/c' to be in goos/goarch format"},
+		{platforms: "linux/amd64,windows", err: "Expected platform 'windows' to be in goos/goarch format"},
+		{platforms: ",", err: "Expected at least one platform in ','"},
+		{cgo: "yes", err: "Expected cgo to be either 1 or 0 but was 'yes'"},
+		{cgo: "2", err: "Expected cgo to be either 1 or 0 but was '2'"},
+	}
+
+	for _, ex := range examples {
+		config, err := linter.NewBuildConfigFromStrs(ex.tags, ex.goos, ex.goarch, ex.platforms, ex.cgo)
+
+		if len(ex.err) > 0 {
+			if err == nil || err.Error() != ex.err {
+				t.Fatalf("Expected platforms '%s' and cgo '%s' to fail with '%s' but was %v", ex.platforms, ex.cgo, ex.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Expected no error but was %v", err)
+		}
+
+		if !reflect.DeepEqual(config, ex.expected) {
+			t.Fatalf("Expected %#v but was %#v", ex.expected, config)
+		}
+	}
+}
+
+func TestBuildConfigEnvAndFlags(t *testing.T) {
+	config := linter.BuildConfig{Tags: []string{"integration", "e2e"}, CgoEnabled: "0"}
+
+	env := config.Env(linter.Platform{GOOS: "linux"})
+	if !reflect.DeepEqual(env, []string{"GOOS=linux", "CGO_ENABLED=0"}) {
+		t.Fatalf("Expected env to only include given values but was %#v", env)
+	}
+
+	flags := config.BuildFlags()
+	if !reflect.DeepEqual(flags, []string{"-tags=integration,e2e"}) {
+		t.Fatalf("Expected tags flag but was %#v", flags)
+	}
+
+	if flags := (linter.BuildConfig{}).BuildFlags(); flags != nil {
+		t.Fatalf("Expected no flags without tags but was %#v", flags)
+	}
+}
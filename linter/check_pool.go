@@ -0,0 +1,97 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// CheckError is an error returned by a single check
+type CheckError struct {
+	checkID string
+	err     error
+}
+
+func (e CheckError) Error() string {
+	return fmt.Sprintf("Check '%s' failed: %s", e.checkID, e.err.Error())
+}
+
+// CheckErrors are errors of all finders and checks that failed in a program
+type CheckErrors struct {
+	errs []error
+}
+
+func (e CheckErrors) Error() string {
+	return fmt.Sprintf("%d check(s) failed", len(e.errs))
+}
+
+func (e CheckErrors) UnderlyingErrs() []error {
+	return e.errs
+}
+
+type checkResult struct {
+	problems []check.Problem
+	err      error
+}
+
+// runChecks runs checks with a fixed number of workers;
+// failed (or panicked) checks do not prevent other checks from running.
+// Problems are returned in the same order as checks.
+func (l linter) runChecks(checks []identifiedCheck) ([]check.Problem, []error) {
+	results := make([]checkResult, len(checks))
+
+	indexesCh := make(chan int)
+
+	// Populated by checking goroutines
+	endCh := make(chan struct{})
+
+	for i := 0; i < l.concurrency; i++ {
+		go func() {
+			for idx := range indexesCh {
+				// Each worker writes to a different result
+				results[idx] = runCheck(checks[idx])
+			}
+
+			endCh <- struct{}{}
+		}()
+	}
+
+	for idx := range checks {
+		indexesCh <- idx
+	}
+
+	close(indexesCh)
+
+	for i := 0; i < l.concurrency; i++ {
+		<-endCh
+	}
+
+	var problems []check.Problem
+	var errs []error
+
+	for _, result := range results {
+		problems = append(problems, result.problems...)
+
+		if result.err != nil {
+			errs = append(errs, result.err)
+		}
+	}
+
+	return problems, errs
+}
+
+func runCheck(c identifiedCheck) (result checkResult) {
+	defer recoverCheck(c.checkID, c.position, &result.err)
+
+	problems, err := c.check.Check()
+	if err != nil {
+		err = CheckError{c.checkID, err}
+	}
+
+	for i := range problems {
+		problems[i].CheckID = c.checkID
+		problems[i].Severity = c.severity
+	}
+
+	return checkResult{problems, err}
+}
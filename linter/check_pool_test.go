@@ -0,0 +1,60 @@
+package linter_test
+
+import (
+	"errors"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestLinterRunFailingChecks(t *testing.T) {
+	fset := token.NewFileSet()
+
+	pkg := parseTestPackage(t, fset, "a", "package a\n")
+
+	succeeding := staticCheck{[]check.Problem{{Text: "first"}, {Text: "second"}}, nil}
+	failing := staticCheck{[]check.Problem{{Text: "partial"}}, errors.New("check err")}
+	empty := staticCheck{nil, nil}
+
+	l := newTestLinter(t,
+		finderDef("succeeding", check.SeverityWarning, fileFinder{[]check.Check{succeeding}}),
+		finderDef("failing", check.SeverityError, fileFinder{[]check.Check{failing, empty}}),
+	)
+
+	problems, err := l.Run(check.NewProgram(fset, []*check.PackageInfo{pkg}))
+
+	var results []string
+
+	for _, problem := range problems {
+		results = append(results, problem.CheckID+" "+string(problem.Severity)+" "+problem.Text)
+	}
+
+	// Problems of failed checks are kept; check IDs and severities are set by the linter
+	expectedResults := []string{
+		"succeeding warning first",
+		"succeeding warning second",
+		"failing error partial",
+	}
+
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Fatalf("Expected problems %#v but was %#v", expectedResults, results)
+	}
+
+	checkErrs, ok := err.(linter.CheckErrors)
+	if !ok {
+		t.Fatalf("Expected check errors but was %#v", err)
+	}
+
+	var errTexts []string
+
+	for _, checkErr := range checkErrs.UnderlyingErrs() {
+		errTexts = append(errTexts, checkErr.Error())
+	}
+
+	if !reflect.DeepEqual(errTexts, []string{"Check 'failing' failed: check err"}) {
+		t.Fatalf("Expected single error of failing check but was %#v", errTexts)
+	}
+}
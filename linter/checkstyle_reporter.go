@@ -0,0 +1,122 @@
+package linter
+
+import (
+	"encoding/xml"
+	"go/ast"
+	"go/types"
+	"io"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+)
+
+type checkstyleResult struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleReporter writes Checkstyle XML on Flush with problems grouped
+// by file; errors are not part of the format hence are displayed by ui
+type checkstyleReporter struct {
+	writer io.Writer
+	ui     UI
+
+	files map[string]*checkstyleFile
+	lock  sync.Mutex
+
+	logger *log.Logger
+}
+
+func NewCheckstyleReporter(writer io.Writer, ui UI, logger *log.Logger) *checkstyleReporter {
+	return &checkstyleReporter{
+		writer: writer,
+		ui:     ui,
+		files:  map[string]*checkstyleFile{},
+		logger: logger,
+	}
+}
+
+func (r *checkstyleReporter) ReportPackage(pkg *types.Package) {}
+
+func (r *checkstyleReporter) ReportFile(*types.Package, *ast.File, string, []string) {}
+
+func (r *checkstyleReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	file, found := r.files[problem.Position.Filename]
+	if !found {
+		file = &checkstyleFile{Name: problem.Position.Filename}
+		r.files[problem.Position.Filename] = file
+	}
+
+	file.Errors = append(file.Errors, checkstyleError{
+		Line:     problem.Position.Line,
+		Column:   problem.Position.Column,
+		Severity: string(problem.Severity), // same as Checkstyle severities
+		Message:  problem.Text,
+		Source:   problem.CheckID,
+	})
+}
+
+func (r *checkstyleReporter) ReportSummary(summary Summary) {}
+
+func (r *checkstyleReporter) DisplayError(err error) {
+	r.ui.DisplayError(err)
+}
+
+// Flush writes files ordered by their path, each with problems
+// ordered by line and column
+func (r *checkstyleReporter) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result := checkstyleResult{Version: "4.3"}
+
+	for _, file := range r.files {
+		sort.SliceStable(file.Errors, func(i, j int) bool {
+			if file.Errors[i].Line != file.Errors[j].Line {
+				return file.Errors[i].Line < file.Errors[j].Line
+			}
+			return file.Errors[i].Column < file.Errors[j].Column
+		})
+		result.Files = append(result.Files, file)
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Name < result.Files[j].Name
+	})
+
+	writeXML(r.writer, result, r.logger)
+
+	return nil
+}
+
+func writeXML(writer io.Writer, value interface{}, logger *log.Logger) {
+	bytes, err := xml.MarshalIndent(value, "", "  ")
+	if err != nil {
+		logger.Printf("Failed to marshal XML: %#v", err)
+		return
+	}
+
+	_, err = writer.Write(append(append([]byte(xml.Header), bytes...), '\n'))
+	if err != nil {
+		logger.Printf("Failed to write XML: %#v", err)
+	}
+}
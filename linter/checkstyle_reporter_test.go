@@ -0,0 +1,72 @@
+package linter_test
+
+import (
+	"bytes"
+	"errors"
+	"go/token"
+	"log"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestCheckstyleReporter(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+
+	reporter := linter.NewCheckstyleReporter(&buf, linter.NewPlainUI(&errBuf, log.New(&errBuf, "", 0)), log.New(&buf, "", 0))
+
+	// Problems are reported in the order they are found
+	for _, problem := range []check.Problem{
+		{CheckID: "errorAssignment", Severity: check.SeverityError, Text: `Return value of "Close" should be used`,
+			Position: token.Position{Filename: "/src/pkg/main.go", Line: 7, Column: 2}},
+		{CheckID: "testPackageSuffix", Severity: check.SeverityInfo, Text: "Test file should be in a corresponding test package",
+			Position: token.Position{Filename: "/src/pkg/main_test.go", Line: 1, Column: 1}},
+		{CheckID: "errorAssignment", Severity: check.SeverityError, Text: "Return value of type error should be used",
+			Position: token.Position{Filename: "/src/pkg/main.go", Line: 3, Column: 9}},
+		{CheckID: "packageDirName", Severity: check.SeverityWarning, Text: "Package name should match directory name & <tests>",
+			Position: token.Position{Filename: "/src/pkg/main.go", Line: 3, Column: 1}},
+		{CheckID: "baseline", Severity: check.SeverityInfo, Text: "Baseline entry no longer matches any problems",
+			Position: token.Position{Filename: "/src/baseline.json", Line: 2}},
+	} {
+		reporter.ReportProblem(problem)
+	}
+
+	reporter.DisplayError(errorWithCauses{"Failed to load github.com/org/broken", []error{errors.New("main.go:1:1: oops")}})
+	reporter.DisplayError(linter.FoundProblemsError{}) // not presentable
+	reporter.ReportSummary(linter.Summary{Packages: 1, Problems: 5})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	// Files are ordered by path and problems by line and column;
+	// severities are same as Checkstyle ones and missing columns are omitted
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="/src/baseline.json">
+    <error line="2" severity="info" message="Baseline entry no longer matches any problems" source="baseline"></error>
+  </file>
+  <file name="/src/pkg/main.go">
+    <error line="3" column="1" severity="warning" message="Package name should match directory name &amp; &lt;tests&gt;" source="packageDirName"></error>
+    <error line="3" column="9" severity="error" message="Return value of type error should be used" source="errorAssignment"></error>
+    <error line="7" column="2" severity="error" message="Return value of &#34;Close&#34; should be used" source="errorAssignment"></error>
+  </file>
+  <file name="/src/pkg/main_test.go">
+    <error line="1" column="1" severity="info" message="Test file should be in a corresponding test package" source="testPackageSuffix"></error>
+  </file>
+</checkstyle>
+`
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %s but was %s", expected, buf.String())
+	}
+
+	// Errors are not part of the format
+	expectedErrs := "\n[error] Failed to load github.com/org/broken\n        - main.go:1:1: oops\n"
+
+	if errBuf.String() != expectedErrs {
+		t.Fatalf("Expected errors %q but was %q", expectedErrs, errBuf.String())
+	}
+}
@@ -3,9 +3,6 @@ package linter
 import (
 	"fmt"
 	"log"
-	"runtime"
-
-	goloader "code.google.com/p/go.tools/go/loader"
 
 	"github.com/cppforlife/lint/check"
 	"github.com/cppforlife/lint/check/fix"
@@ -15,117 +12,174 @@ type cli struct {
 	ui     UI
 	loader Loader
 	linter Linter
+
+	// Maximum number of programs linted concurrently
+	concurrency int
+
 	logger *log.Logger
 }
 
-func NewCLI(ui UI, loader Loader, linter Linter, logger *log.Logger) cli {
-	return cli{ui, loader, linter, logger}
+type lintResult struct {
+	problems []check.Problem
+	err      error
+}
+
+func NewCLI(ui UI, loader Loader, linter Linter, concurrency int, logger *log.Logger) cli {
+	return cli{ui, loader, linter, concurrency, logger}
 }
 
+// Run displays all encountered errors and returns the one that
+// determines exit code (see ExitCode) with all found problems
 func (c cli) Run(shouldFixProblems bool) error {
-	c.setGOMAXPROCS()
+	if c.concurrency < 1 {
+		err := fmt.Errorf("Concurrency must be at least 1 but was %d", c.concurrency)
+		c.ui.DisplayError(err)
+		return err
+	}
 
 	programsCh, loaderErrsCh, err := c.loader.Programs()
 	if err != nil {
-		return fmt.Errorf("Loading programs %#v", err)
+		err = LoadError{"packages", err, nil}
+		c.ui.DisplayError(err)
+		return err
 	}
 
-	numPrograms := 0
+	resultsCh := c.lintPrograms(programsCh)
 
-	problemssCh := make(chan []check.Problem)
-	linterErrsCh := make(chan error)
+	var worstErr error
 
-	for program := range programsCh {
-		numPrograms++
-
-		go func(program *goloader.Program) {
-			problems, err := c.linter.Run(program)
-			linterErrsCh <- err
-			problemssCh <- problems
-		}(program)
+	fixes, err := c.drainResults(resultsCh, shouldFixProblems)
+	if err != nil {
+		worstErr = worseError(worstErr, err)
 	}
 
-	var lastErr error
-
-	err = c.drainLoaderErrs(loaderErrsCh)
+	finishFixes, err := c.finish()
 	if err != nil {
-		lastErr = err
+		worstErr = worseError(worstErr, err)
 	}
 
-	err = c.drainLinterErrs(linterErrsCh, numPrograms)
+	fixes = append(fixes, finishFixes...)
+
+	err = c.drainLoaderErrs(loaderErrsCh)
 	if err != nil {
-		lastErr = err
+		worstErr = worseError(worstErr, err)
 	}
 
-	fixes := c.drainProblems(problemssCh, numPrograms)
-
 	if shouldFixProblems {
 		err = c.applyFixes(fixes)
 		if err != nil {
-			lastErr = err
+			worstErr = worseError(worstErr, err)
 		}
 	}
 
-	return lastErr
+	return worstErr
 }
 
-func (c cli) setGOMAXPROCS() {
-	numCPU := runtime.NumCPU()
+// lintPrograms lints programs as soon as they are loaded
+// with a fixed number of workers. Loader waits for workers
+// to pick up programs so that only few are kept in memory.
+func (c cli) lintPrograms(programsCh <-chan *check.Program) <-chan lintResult {
+	resultsCh := make(chan lintResult)
+
+	// Populated by linting goroutines
+	endCh := make(chan struct{})
 
-	c.logger.Printf("Setting GOMAXPROCS=%d\n", numCPU)
+	for i := 0; i < c.concurrency; i++ {
+		go func() {
+			for program := range programsCh {
+				problems, err := c.linter.Run(program)
+				resultsCh <- lintResult{problems, err}
+			}
 
-	runtime.GOMAXPROCS(numCPU)
+			endCh <- struct{}{}
+		}()
+	}
+
+	// Wait for all programs to be linted
+	go func() {
+		for i := 0; i < c.concurrency; i++ {
+			<-endCh
+		}
+		close(resultsCh)
+		close(endCh)
+	}()
+
+	return resultsCh
 }
 
 func (c cli) drainLoaderErrs(errsCh <-chan error) error {
-	var lastErr error
+	var worstErr error
 
 	for err := range errsCh {
 		if err != nil {
-			lastErr = err
+			worstErr = worseError(worstErr, err)
 			c.ui.DisplayError(err)
 		}
 	}
 
-	return lastErr
+	return worstErr
 }
 
-func (c cli) drainLinterErrs(errsCh chan error, numPrograms int) error {
-	var lastErr error
+// drainResults only keeps fixes (if they are going to be applied)
+// so that problems and their programs could be garbage collected
+// (fixes refer to parsed files)
+func (c cli) drainResults(resultsCh <-chan lintResult, keepFixes bool) ([]fix.Fix, error) {
+	var fixes []fix.Fix
+	var worstErr error
 
-	for i := 0; i < numPrograms; i++ {
-		err := <-errsCh
-		if err != nil {
-			lastErr = err
-			c.ui.DisplayError(err)
+	for result := range resultsCh {
+		if result.err != nil {
+			worstErr = worseError(worstErr, result.err)
+			c.ui.DisplayError(result.err)
+		}
+
+		if keepFixes {
+			for _, problem := range result.problems {
+				fixes = append(fixes, problem.Fixes...)
+			}
 		}
 	}
 
-	return lastErr
+	return fixes, worstErr
 }
 
-func (c cli) drainProblems(problemssCh chan []check.Problem, numPrograms int) []fix.Fix {
+func (c cli) finish() ([]fix.Fix, error) {
 	var fixes []fix.Fix
 
-	for i := 0; i < numPrograms; i++ {
-		for _, problem := range <-problemssCh {
-			fixes = append(fixes, problem.Fixes...)
-		}
+	problems, err := c.linter.Finish()
+	if err != nil {
+		c.ui.DisplayError(err)
 	}
 
-	return fixes
+	for _, problem := range problems {
+		fixes = append(fixes, problem.Fixes...)
+	}
+
+	return fixes, err
 }
 
+// applyFixes describes all fixes before applying any of them so that
+// their edits (e.g. presented by SARIF reporter after fixes are applied)
+// are relative to files before they were fixed
 func (c cli) applyFixes(fixes []fix.Fix) error {
-	var lastErr error
+	var worstErr error
+
+	for _, f := range fixes {
+		if editsFix, ok := f.(fix.EditsFix); ok {
+			_, err := editsFix.Edits()
+			if err != nil {
+				c.logger.Printf("Failed to describe fix '%s': %s\n", f.NameStr(), err)
+			}
+		}
+	}
 
-	for _, fix := range fixes {
+	for _, fix := range fix.CombineTextEdits(fixes) {
 		err := fix.Fix()
 		if err != nil {
-			lastErr = err
+			worstErr = worseError(worstErr, err)
 			c.ui.DisplayError(err)
 		}
 	}
 
-	return lastErr
+	return worstErr
 }
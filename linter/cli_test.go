@@ -0,0 +1,85 @@
+package linter_test
+
+import (
+	"go/token"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+	"github.com/cppforlife/lint/linter"
+)
+
+// programsLoader returns given programs without errors
+type programsLoader struct {
+	programs []*check.Program
+}
+
+func (l programsLoader) Programs() (<-chan *check.Program, <-chan error, error) {
+	programsCh := make(chan *check.Program, len(l.programs))
+	errsCh := make(chan error)
+
+	for _, program := range l.programs {
+		programsCh <- program
+	}
+
+	close(programsCh)
+	close(errsCh)
+
+	return programsCh, errsCh, nil
+}
+
+type discardUI struct{}
+
+func (discardUI) DisplayError(error) {}
+
+// recordingFix records when its edits are described and when it is applied
+type recordingFix struct {
+	fix.SimpleDiff
+	calls *[]string
+}
+
+func (f recordingFix) Fix() error {
+	*f.calls = append(*f.calls, "fix")
+	return nil
+}
+
+func (f recordingFix) Edits() ([]fix.TextEdit, error) {
+	*f.calls = append(*f.calls, "edits")
+	return nil, nil
+}
+
+func TestCLIRunDescribesFixesOnlyWhenFixing(t *testing.T) {
+	for _, shouldFix := range []bool{false, true} {
+		var calls []string
+
+		fset := token.NewFileSet()
+		pkg := parseTestPackage(t, fset, "a", "package a\n")
+
+		found := staticCheck{[]check.Problem{{
+			Text:    "problem",
+			Package: pkg.Pkg,
+			Fixes:   []fix.Fix{recordingFix{fix.SimpleDiff{Name: "name"}, &calls}},
+		}}, nil}
+
+		l := newTestLinter(t, finderDef("found", check.SeverityWarning, fileFinder{[]check.Check{found}}))
+		loader := programsLoader{[]*check.Program{check.NewProgram(fset, []*check.PackageInfo{pkg})}}
+
+		err := linter.NewCLI(discardUI{}, loader, l, 1, log.New(ioutil.Discard, "", 0)).Run(shouldFix)
+		if _, ok := err.(linter.FoundProblemsError); !ok {
+			t.Fatalf("Expected found problems but was %#v", err)
+		}
+
+		// Fixes are described before any of them are applied
+		var expected []string
+		if shouldFix {
+			expected = []string{"edits", "fix"}
+		}
+
+		if !reflect.DeepEqual(calls, expected) {
+			t.Fatalf("Expected fix calls %#v when fixing is %t but was %#v", expected, shouldFix, calls)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// ConfigFileName is looked up in the lint root directory and its parents
+const ConfigFileName = ".lint.json"
+
+// Config is a project configuration file e.g.
+//
+//	{
+//	  "disable": ["ginkgoSuiteTestFile"],
+//	  "exclude": ["fixtures", "*_generated.go"],
+//	  "checks": {"errorAssignment": {"ignoreFuncs": ["fmt.Print*"]}},
+//	  "severity": {"packageDirName": "error"},
+//	  "plugins": [{"id": "noPanics", "command": ["./bin/lint-no-panics"]}],
+//	  "overrides": [{"paths": ["integration/..."], "disable": ["testPackageSuffix"]}]
+//	}
+//
+// Paths are relative to the directory of the configuration file.
+type Config struct {
+	Enable  []string `json:"enable"`
+	Disable []string `json:"disable"`
+
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+
+	// Options for each check by check ID
+	Checks map[string]check.Options `json:"checks"`
+
+	// Severity for each check by check ID
+	Severity map[string]check.Severity `json:"severity"`
+
+	Overrides []ConfigOverride `json:"overrides"`
+
+	// External checks registered in addition to built-in ones
+	Plugins []ConfigPlugin `json:"plugins"`
+
+	// Directory of the configuration file
+	Dir string `json:"-"`
+}
+
+// ConfigOverride changes enabled checks and their options
+// for directories matching paths (e.g. "integration/...", "cmd/*")
+type ConfigOverride struct {
+	Paths []string `json:"paths"`
+
+	Enable  []string `json:"enable"`
+	Disable []string `json:"disable"`
+
+	// Options are merged with top level options for each check
+	Checks map[string]check.Options `json:"checks"`
+
+	Severity map[string]check.Severity `json:"severity"`
+}
+
+// ConfigPlugin declares a check implemented by an executable
+// (see check.PluginRequest and check.PluginResponse)
+type ConfigPlugin struct {
+	ID          string         `json:"id"`
+	Description string         `json:"description"`
+	Command     []string       `json:"command"`
+	Severity    check.Severity `json:"severity"`
+
+	// Maximum duration of a single run e.g. "30s" (DefaultPluginTimeout by default)
+	Timeout string `json:"timeout"`
+}
+
+// DefaultPluginTimeout is used for plugins without a configured timeout
+const DefaultPluginTimeout = time.Minute
+
+// CheckFinder is a finder for an enabled check; finder is one of
+// check.Finder, check.NodeFinder, check.PackageFinder or check.ProgramFinder
+type CheckFinder struct {
+	check.Definition
+	check.AnyFinder
+}
+
+// FindConfig looks for configuration file in dir and its parents;
+// empty configuration is returned if no file is found
+func FindConfig(dir string) (Config, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return Config{}, fmt.Errorf("Expanding config dir %#v", err)
+	}
+
+	for d := absDir; ; d = filepath.Dir(d) {
+		path := filepath.Join(d, ConfigFileName)
+
+		if _, err := os.Stat(path); err == nil {
+			return LoadConfig(path)
+		}
+
+		if filepath.Dir(d) == d {
+			return Config{Dir: absDir}, nil
+		}
+	}
+}
+
+func LoadConfig(path string) (Config, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("Expanding config path %#v", err)
+	}
+
+	bytes, err := ioutil.ReadFile(absPath)
+	if err != nil {
+		return Config{}, fmt.Errorf("Reading config %#v", err)
+	}
+
+	var config Config
+
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("Parsing config %s: %s", absPath, err.Error())
+	}
+
+	config.Dir = filepath.Dir(absPath)
+
+	return config, nil
+}
+
+// RegisterPlugins adds plugin checks to the registry (enabled by default);
+// relative command paths are relative to the configuration file
+func (c Config) RegisterPlugins(registry *check.Registry) error {
+	for _, plugin := range c.Plugins {
+		plugin := plugin
+
+		if len(plugin.Command) == 0 {
+			return fmt.Errorf("Plugin '%s' must specify a command", plugin.ID)
+		}
+
+		command := append([]string{}, plugin.Command...)
+
+		if strings.ContainsRune(command[0], '/') && !filepath.IsAbs(command[0]) {
+			command[0] = filepath.Join(c.Dir, filepath.FromSlash(command[0]))
+		}
+
+		timeout := DefaultPluginTimeout
+
+		if len(plugin.Timeout) > 0 {
+			var err error
+
+			timeout, err = time.ParseDuration(plugin.Timeout)
+			if err != nil {
+				return fmt.Errorf("Parsing timeout of plugin '%s': %s", plugin.ID, err.Error())
+			}
+
+			if timeout <= 0 {
+				return fmt.Errorf("Plugin '%s' must specify a positive timeout", plugin.ID)
+			}
+		}
+
+		description := plugin.Description
+		if len(description) == 0 {
+			description = "Plugin " + strings.Join(plugin.Command, " ")
+		}
+
+		err := registry.Register(check.Definition{
+			ID:               plugin.ID,
+			Description:      description,
+			EnabledByDefault: true,
+			Severity:         plugin.Severity,
+			NewFinder: func(opts check.Options) (check.AnyFinder, error) {
+				return check.NewPluginFinder(plugin.ID, command, timeout, opts)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Registering plugin '%s': %s", plugin.ID, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Validate makes sure that check IDs, options and paths are valid;
+// each override is validated on its own (with its options merged
+// with top level options) regardless of directories it matches
+func (c Config) Validate(registry *check.Registry) error {
+	err := c.validateChecks(registry, c.Enable, c.Disable, []map[string]check.Options{c.Checks}, c.Severity)
+	if err != nil {
+		return err
+	}
+
+	for _, override := range c.Overrides {
+		if len(override.Paths) == 0 {
+			return fmt.Errorf("Config override must specify at least one path")
+		}
+
+		optionsByID := []map[string]check.Options{c.Checks, override.Checks}
+
+		err := c.validateChecks(registry, override.Enable, override.Disable, optionsByID, override.Severity)
+		if err != nil {
+			return fmt.Errorf("Config override for '%s': %s", strings.Join(override.Paths, ", "), err.Error())
+		}
+	}
+
+	return NewPathFilter(c.Dir, c.Include, c.Exclude).validate()
+}
+
+// validateChecks makes sure that selected checks are known and that
+// finders of checks with options (last in optionsByID) could be configured
+func (c Config) validateChecks(
+	registry *check.Registry,
+	enable, disable []string,
+	optionsByID []map[string]check.Options,
+	severityByID map[string]check.Severity,
+) error {
+	_, err := registry.Select(check.Selection{Enable: enable, Disable: disable})
+	if err != nil {
+		return err
+	}
+
+	for id := range severityByID {
+		if _, found := registry.Find(id); !found {
+			return fmt.Errorf("Configuring severity of unknown check '%s'", id)
+		}
+	}
+
+	var ids []string
+
+	for id := range optionsByID[len(optionsByID)-1] {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		def, found := registry.Find(id)
+		if !found {
+			return fmt.Errorf("Configuring unknown check '%s'", id)
+		}
+
+		options, err := c.mergeOptions(optionsByID, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = def.NewFinder(options)
+		if err != nil {
+			return fmt.Errorf("Configuring check '%s': %s", id, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Finders returns finders for checks enabled in dir configured with check options;
+// selection (e.g. from command line flags) takes precedence over configuration
+func (c Config) Finders(registry *check.Registry, dir string, selection check.Selection) ([]CheckFinder, error) {
+	selections := []check.Selection{{Enable: c.Enable, Disable: c.Disable}}
+	optionsByID := []map[string]check.Options{c.Checks}
+	severityByID := []map[string]check.Severity{c.Severity}
+
+	for _, override := range c.Overrides {
+		if c.overrideMatches(override, dir) {
+			selections = append(selections, check.Selection{Enable: override.Enable, Disable: override.Disable})
+			optionsByID = append(optionsByID, override.Checks)
+			severityByID = append(severityByID, override.Severity)
+		}
+	}
+
+	selections = append(selections, selection)
+
+	defs, err := registry.Select(selections...)
+	if err != nil {
+		return nil, fmt.Errorf("Selecting checks for %s: %s", dir, err.Error())
+	}
+
+	for _, opts := range optionsByID {
+		for id := range opts {
+			if _, found := registry.Find(id); !found {
+				return nil, fmt.Errorf("Configuring unknown check '%s'", id)
+			}
+		}
+	}
+
+	for _, severities := range severityByID {
+		for id := range severities {
+			if _, found := registry.Find(id); !found {
+				return nil, fmt.Errorf("Configuring severity of unknown check '%s'", id)
+			}
+		}
+	}
+
+	var finders []CheckFinder
+
+	for _, def := range defs {
+		options, err := c.mergeOptions(optionsByID, def.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		finder, err := def.NewFinder(options)
+		if err != nil {
+			return nil, fmt.Errorf("Configuring check '%s': %s", def.ID, err.Error())
+		}
+
+		switch finder.(type) {
+		case check.Finder, check.NodeFinder, check.PackageFinder, check.ProgramFinder:
+		default:
+			return nil, fmt.Errorf("Check '%s' has unsupported finder %T", def.ID, finder)
+		}
+
+		// Later overrides take precedence
+		for _, severities := range severityByID {
+			if severity, found := severities[def.ID]; found {
+				def.Severity = severity
+			}
+		}
+
+		finders = append(finders, CheckFinder{def, finder})
+	}
+
+	return finders, nil
+}
+
+func (c Config) overrideMatches(override ConfigOverride, dir string) bool {
+	relDir, err := filepath.Rel(c.Dir, dir)
+	if err != nil {
+		return false
+	}
+
+	relDir = filepath.ToSlash(relDir)
+
+	for _, path := range override.Paths {
+		if matchPattern(path)(relDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeOptions merges top level JSON objects so that
+// overrides only need to specify changed option names
+func (c Config) mergeOptions(optionsByID []map[string]check.Options, id string) (check.Options, error) {
+	var merged map[string]json.RawMessage
+
+	for _, opts := range optionsByID {
+		options, found := opts[id]
+		if !found {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+
+		err := json.Unmarshal(options, &fields)
+		if err != nil {
+			return nil, fmt.Errorf("Expected options for check '%s' to be an object", id)
+		}
+
+		if merged == nil {
+			merged = map[string]json.RawMessage{}
+		}
+
+		for name, value := range fields {
+			merged[name] = value
+		}
+	}
+
+	if merged == nil {
+		return nil, nil
+	}
+
+	bytes, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("Merging options for check '%s' %#v", id, err)
+	}
+
+	return check.Options(bytes), nil
+}
@@ -0,0 +1,146 @@
+package linter_test
+
+import (
+	"encoding/json"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestConfigValidate(t *testing.T) {
+	examples := []struct {
+		config string
+		err    string
+	}{
+		{`{}`, ""},
+		{
+			`{"checks": {"errorAssignment": {"ignoreFuncs": ["fmt.Print*"]}},
+			  "overrides": [{"paths": ["integration/...", "cmd/*"], "disable": ["testPackageSuffix"],
+			    "checks": {"errorAssignment": {"ignoreFuncs": ["os.*"]}}, "severity": {"errorAssignment": "info"}}]}`,
+			"",
+		},
+		{`{"enable": ["unknown"]}`, "Unknown check 'unknown'"},
+		{`{"checks": {"unknown": {}}}`, "Configuring unknown check 'unknown'"},
+		{`{"severity": {"unknown": "info"}}`, "Configuring severity of unknown check 'unknown'"},
+		{`{"checks": {"errorAssignment": []}}`, "Expected options for check 'errorAssignment' to be an object"},
+		{`{"overrides": [{"disable": ["testPackageSuffix"]}]}`, "Config override must specify at least one path"},
+
+		// Overrides are validated regardless of directories they match
+		{
+			`{"overrides": [{"paths": ["cmd/*", "integration/..."], "disable": ["unknown"]}]}`,
+			"Config override for 'cmd/*, integration/...': Unknown check 'unknown'",
+		},
+		{
+			`{"overrides": [{"paths": ["integration"]}, {"paths": ["a", "b/..."], "severity": {"unknown": "info"}}]}`,
+			"Config override for 'a, b/...': Configuring severity of unknown check 'unknown'",
+		},
+		{
+			`{"overrides": [{"paths": ["integration/..."], "checks": {"unknown": {}}}]}`,
+			"Config override for 'integration/...': Configuring unknown check 'unknown'",
+		},
+		{
+			// Override options are merged with top level options
+			`{"checks": {"errorAssignment": {"ignoreFuncs": ["fmt.Print*"]}},
+			  "overrides": [{"paths": ["integration/..."], "checks": {"errorAssignment": {"ignoreFuncs": "os.*"}}}]}`,
+			"Config override for 'integration/...': Configuring check 'errorAssignment': " +
+				"Decoding options json: cannot unmarshal string into Go struct field errorAssignmentsOpts.ignoreFuncs of type []string",
+		},
+	}
+
+	for _, ex := range examples {
+		var config linter.Config
+
+		err := json.Unmarshal([]byte(ex.config), &config)
+		if err != nil {
+			t.Fatalf("Unmarshal %v", err)
+		}
+
+		config.Dir = "/src/project"
+
+		err = config.Validate(check.NewDefaultRegistry())
+
+		if len(ex.err) == 0 {
+			if err != nil {
+				t.Fatalf("Expected config %s to be valid but was %v", ex.config, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != ex.err {
+			t.Fatalf("Expected config %s to fail with '%s' but was %v", ex.config, ex.err, err)
+		}
+	}
+}
+
+func TestConfigRegisterPluginsTimeout(t *testing.T) {
+	dir := t.TempDir()
+
+	err := ioutil.WriteFile(filepath.Join(dir, "slow.sh"), []byte("#!/bin/sh\nexec sleep 5\n"), 0700)
+	if err != nil {
+		t.Fatalf("WriteFile %v", err)
+	}
+
+	var config linter.Config
+
+	err = json.Unmarshal([]byte(`{"plugins": [{"id": "slow", "command": ["./slow.sh"], "timeout": "100ms"}]}`), &config)
+	if err != nil {
+		t.Fatalf("Unmarshal %v", err)
+	}
+
+	config.Dir = dir
+
+	registry := &check.Registry{}
+
+	err = config.RegisterPlugins(registry)
+	if err != nil {
+		t.Fatalf("RegisterPlugins %v", err)
+	}
+
+	fset := token.NewFileSet()
+
+	pkg := parseTestPackage(t, fset, "a", "package a\n")
+	pkg.Dir = dir
+
+	l := linter.NewLinter(discardReporter{}, registry, config, check.Selection{},
+		false, nil, nil, 2, log.New(ioutil.Discard, "", 0))
+
+	_, err = l.Run(check.NewProgram(fset, []*check.PackageInfo{pkg}))
+
+	checkErrs, ok := err.(linter.CheckErrors)
+	if !ok || len(checkErrs.UnderlyingErrs()) != 1 {
+		t.Fatalf("Expected single check error but was %#v", err)
+	}
+
+	expected := "Running plugin '" + filepath.Join(dir, "slow.sh") + "': timed out after 100ms"
+
+	if !strings.Contains(checkErrs.UnderlyingErrs()[0].Error(), expected) {
+		t.Fatalf("Expected check error to include '%s' but was '%s'", expected, checkErrs.UnderlyingErrs()[0].Error())
+	}
+}
+
+func TestConfigRegisterPluginsInvalidTimeout(t *testing.T) {
+	examples := []struct {
+		timeout string
+		err     string
+	}{
+		{"soon", `Parsing timeout of plugin 'slow': time: invalid duration "soon"`},
+		{"0s", "Plugin 'slow' must specify a positive timeout"},
+	}
+
+	for _, ex := range examples {
+		config := linter.Config{
+			Plugins: []linter.ConfigPlugin{{ID: "slow", Command: []string{"./slow.sh"}, Timeout: ex.timeout}},
+		}
+
+		err := config.RegisterPlugins(&check.Registry{})
+		if err == nil || err.Error() != ex.err {
+			t.Fatalf("Expected timeout '%s' to fail with '%s' but was %v", ex.timeout, ex.err, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package linter
+
+import (
+	"github.com/cppforlife/lint/check"
+)
+
+// Exit codes allow CI to tell apart why linting failed
+const (
+	ExitCodeOK         = 0
+	ExitCodeProblems   = 1
+	ExitCodeLoadErrors = 2
+	ExitCodeInternal   = 3
+)
+
+// ExitCode returns exit code for an error returned by cli;
+// problems less severe than failOn do not fail linting
+func ExitCode(err error, failOn check.Severity) int {
+	switch e := err.(type) {
+	case nil:
+		return ExitCodeOK
+
+	case FoundProblemsError:
+		// Packages that failed to type-check were linted partially
+		if e.packageErrors > 0 {
+			return ExitCodeLoadErrors
+		}
+		if e.Count(failOn) > 0 {
+			return ExitCodeProblems
+		}
+		return ExitCodeOK
+
+	case LoadError:
+		return ExitCodeLoadErrors
+
+	default:
+		return ExitCodeInternal
+	}
+}
+
+// worseError keeps the error resulting in a higher exit code;
+// found problems are added up so that none are lost
+func worseError(a, b error) error {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	foundA, okA := a.(FoundProblemsError)
+	foundB, okB := b.(FoundProblemsError)
+
+	if okA && okB {
+		return foundA.Add(foundB)
+	}
+
+	// Severity threshold does not matter when comparing different errors
+	if ExitCode(b, check.SeverityInfo) > ExitCode(a, check.SeverityInfo) {
+		return b
+	}
+
+	return a
+}
@@ -0,0 +1,70 @@
+package linter_test
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestExitCode(t *testing.T) {
+	warning := foundProblem(t, check.SeverityWarning, nil)
+	pkgErrors := foundProblem(t, check.SeverityInfo, []check.PackageError{{Msg: "undefined: x"}})
+
+	examples := []struct {
+		name     string
+		err      error
+		failOn   check.Severity
+		expected int
+	}{
+		{"no error", nil, check.SeverityWarning, linter.ExitCodeOK},
+		{"problems at threshold", warning, check.SeverityWarning, linter.ExitCodeProblems},
+		{"problems above threshold", warning, check.SeverityInfo, linter.ExitCodeProblems},
+		{"problems below threshold", warning, check.SeverityError, linter.ExitCodeOK},
+		{"package errors", pkgErrors, check.SeverityError, linter.ExitCodeLoadErrors},
+		{"load error", linter.LoadError{}, check.SeverityWarning, linter.ExitCodeLoadErrors},
+		{"check errors", linter.CheckErrors{}, check.SeverityWarning, linter.ExitCodeInternal},
+		{"wrapped internal error", fmt.Errorf("Linting: %w", errors.New("err")), check.SeverityWarning, linter.ExitCodeInternal},
+	}
+
+	for _, ex := range examples {
+		if code := linter.ExitCode(ex.err, ex.failOn); code != ex.expected {
+			t.Fatalf("Expected %s to exit with %d but was %d", ex.name, ex.expected, code)
+		}
+	}
+}
+
+func TestFoundProblemsErrorCountsProblemsOfAllSeverities(t *testing.T) {
+	info := foundProblem(t, check.SeverityInfo, nil).(linter.FoundProblemsError)
+	warning := foundProblem(t, check.SeverityWarning, nil).(linter.FoundProblemsError)
+
+	if info.Error() != "1 problem found" {
+		t.Fatalf("Expected single problem but was '%s'", info.Error())
+	}
+
+	if err := info.Add(warning); err.Error() != "2 problems found" {
+		t.Fatalf("Expected two problems but was '%s'", err.Error())
+	}
+}
+
+// foundProblem lints a package where a single problem of given severity is found
+func foundProblem(t *testing.T, severity check.Severity, pkgErrs []check.PackageError) error {
+	fset := token.NewFileSet()
+
+	pkg := parseTestPackage(t, fset, "a", "package a\n")
+	pkg.Errors = pkgErrs
+
+	found := staticCheck{[]check.Problem{{Text: "problem", Package: pkg.Pkg}}, nil}
+
+	l := newTestLinter(t, finderDef("found", severity, fileFinder{[]check.Check{found}}))
+
+	_, err := l.Run(check.NewProgram(fset, []*check.PackageInfo{pkg}))
+	if _, ok := err.(linter.FoundProblemsError); !ok {
+		t.Fatalf("Expected found problems but was %#v", err)
+	}
+
+	return err
+}
@@ -0,0 +1,265 @@
+package linter
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+)
+
+// IgnoreDirectiveCheckID is used for problems found
+// in malformed suppression comments
+const IgnoreDirectiveCheckID = "ignoreDirective"
+
+const (
+	ignoreDirectiveName     = "//lint:ignore"
+	fileIgnoreDirectiveName = "//lint:file-ignore"
+)
+
+// ignoreDirective suppresses problems found by listed checks e.g. "//lint:ignore
+// errorAssignment closing read-only file" or "//lint:file-ignore packageDirName generated code"
+type ignoreDirective struct {
+	checkIDs []string
+	reason   string
+
+	// Lines of the node following the comment (or the line
+	// the comment is on for trailing comments); zero endLine
+	// means that the whole file is covered
+	filename  string
+	startLine int
+	endLine   int
+
+	// Kept to report and remove unused comments
+	pkg      *types.Package
+	position token.Position
+	comment  *ast.Comment
+	file     *ast.File
+	fset     *token.FileSet
+}
+
+type ignoreDirectives []ignoreDirective
+
+// newIgnoreDirectives collects suppression comments from package files;
+// malformed comments are returned as problems
+func newIgnoreDirectives(
+	pkg *check.PackageInfo,
+	fset *token.FileSet,
+	registry *check.Registry,
+) (ignoreDirectives, []check.Problem) {
+	var directives ignoreDirectives
+	var problems []check.Problem
+
+	for _, file := range pkg.Files {
+		var lines map[int]nodeSpan
+
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				fields := strings.Fields(comment.Text)
+				if len(fields) == 0 {
+					continue
+				}
+
+				if fields[0] != ignoreDirectiveName && fields[0] != fileIgnoreDirectiveName {
+					continue
+				}
+
+				position := fset.Position(comment.Slash)
+
+				problem := check.Problem{
+					CheckID:  IgnoreDirectiveCheckID,
+					Severity: ignoreDirectiveSeverity,
+					Package:  pkg.Pkg,
+					Position: position,
+					Context:  check.Context{"comment": comment.Text},
+				}
+
+				if len(fields) < 3 {
+					problem.Text = "Suppression comment should specify check IDs and a reason"
+					problems = append(problems, problem)
+					continue
+				}
+
+				checkIDs := strings.Split(fields[1], ",")
+
+				if unknownID, found := unknownCheckID(registry, checkIDs); found {
+					problem.Text = "Suppression comment should refer to known check IDs"
+					problem.Context["checkID"] = unknownID
+					problems = append(problems, problem)
+					continue
+				}
+
+				directive := ignoreDirective{
+					checkIDs: checkIDs,
+					reason:   strings.Join(fields[2:], " "),
+					filename: position.Filename,
+					pkg:      pkg.Pkg,
+					position: position,
+					comment:  comment,
+					file:     file,
+					fset:     fset,
+				}
+
+				if fields[0] == ignoreDirectiveName {
+					if lines == nil {
+						lines = nodeSpansByLine(file, fset)
+					}
+
+					// Trailing comment applies to the code on the same line
+					line := fset.Position(group.End()).Line + 1
+					if span, found := lines[position.Line]; found && span.column < position.Column {
+						line = position.Line
+					}
+
+					directive.startLine = line
+					directive.endLine = line
+
+					if span, found := lines[line]; found {
+						directive.endLine = span.endLine
+					}
+				}
+
+				directives = append(directives, directive)
+			}
+		}
+	}
+
+	return directives, problems
+}
+
+// Filter returns problems that are not suppressed
+// and directives that suppressed each of the other problems
+func (ds ignoreDirectives) Filter(problems []check.Problem) ([]check.Problem, ignoreDirectives) {
+	var kept []check.Problem
+	var used ignoreDirectives
+
+	for _, problem := range problems {
+		if d, found := ds.suppressing(problem); found {
+			used = append(used, d)
+		} else {
+			kept = append(kept, problem)
+		}
+	}
+
+	return kept, used
+}
+
+// Ran returns directives for which all listed checks were run;
+// others cannot be considered unused
+func (ds ignoreDirectives) Ran(checkIDs map[string]bool) ignoreDirectives {
+	var ran ignoreDirectives
+
+	for _, d := range ds {
+		allRan := true
+
+		for _, id := range d.checkIDs {
+			allRan = allRan && checkIDs[id]
+		}
+
+		if allRan {
+			ran = append(ran, d)
+		}
+	}
+
+	return ran
+}
+
+func (ds ignoreDirectives) suppressing(problem check.Problem) (ignoreDirective, bool) {
+	for _, d := range ds {
+		if d.Matches(problem) {
+			return d, true
+		}
+	}
+
+	return ignoreDirective{}, false
+}
+
+func (d ignoreDirective) Matches(problem check.Problem) bool {
+	if problem.Position.Filename != d.filename {
+		return false
+	}
+
+	if d.endLine > 0 {
+		line := problem.Position.Line
+		if line < d.startLine || line > d.endLine {
+			return false
+		}
+	}
+
+	for _, id := range d.checkIDs {
+		if id == problem.CheckID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d ignoreDirective) Key() string { return d.position.String() }
+
+// UnusedProblem suggests removing comment that did not suppress any problems
+func (d ignoreDirective) UnusedProblem() check.Problem {
+	diff := fix.SimpleDiff{
+		Name:    "comment",
+		Current: d.comment.Text,
+		Desired: "(removed)",
+	}
+
+	return check.Problem{
+		CheckID:  IgnoreDirectiveCheckID,
+		Severity: ignoreDirectiveSeverity,
+		Text:     "Suppression comment should suppress at least one problem",
+		Package:  d.pkg,
+		Position: d.position,
+		Context: check.Context{
+			"reason": d.reason,
+		},
+		Fixes: []fix.Fix{fix.NewCommentRemoval(diff, d.file, d.comment, d.fset)},
+	}
+}
+
+func unknownCheckID(registry *check.Registry, checkIDs []string) (string, bool) {
+	for _, id := range checkIDs {
+		if id == PackageErrorsCheckID {
+			continue
+		}
+
+		if _, found := registry.Find(id); !found {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+type nodeSpan struct {
+	column  int
+	endLine int
+}
+
+// nodeSpansByLine finds outermost node starting on each line
+// so that a comment before a block covers the whole block
+func nodeSpansByLine(file *ast.File, fset *token.FileSet) map[int]nodeSpan {
+	spans := map[int]nodeSpan{}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.File:
+			return true
+		case *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+
+		start := fset.Position(n.Pos())
+
+		if _, found := spans[start.Line]; !found {
+			spans[start.Line] = nodeSpan{start.Column, fset.Position(n.End()).Line}
+		}
+
+		return true
+	})
+
+	return spans
+}
@@ -0,0 +1,37 @@
+package linter
+
+import (
+	"fmt"
+	"go/token"
+	"runtime/debug"
+	"strings"
+)
+
+// InternalCheckError is a panic recovered from a finder or a check;
+// problematic node (or file, package) is skipped and linting continues
+type InternalCheckError struct {
+	checkID   string
+	position  token.Position
+	recovered interface{}
+	stack     []byte
+}
+
+func (e InternalCheckError) Error() string {
+	position := "-"
+	if e.position.IsValid() {
+		position = e.position.String()
+	}
+
+	return fmt.Sprintf(
+		"Internal check error in '%s' at %s: %v\n%s",
+		e.checkID, position, e.recovered, strings.TrimSpace(string(e.stack)),
+	)
+}
+
+// recoverCheck turns a panic into an internal check error;
+// must be deferred directly so that recover stops the panic
+func recoverCheck(checkID string, position token.Position, err *error) {
+	if r := recover(); r != nil {
+		*err = InternalCheckError{checkID, position, r, debug.Stack()}
+	}
+}
@@ -0,0 +1,237 @@
+package linter
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/types"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+)
+
+// JSONResult is written as a single document by the json format
+type JSONResult struct {
+	Packages []JSONPackage `json:"packages"`
+	Problems []JSONProblem `json:"problems"`
+	Errors   []JSONError   `json:"errors"`
+	Summary  *Summary      `json:"summary,omitempty"`
+}
+
+// JSONEvent is written on its own line by the ndjson format;
+// only field corresponding to the type is set
+type JSONEvent struct {
+	Type string `json:"type"` // package, problem, error or summary
+
+	Package *JSONPackage `json:"package,omitempty"`
+	Problem *JSONProblem `json:"problem,omitempty"`
+	Error   *JSONError   `json:"error,omitempty"`
+	Summary *Summary     `json:"summary,omitempty"`
+}
+
+type JSONPackage struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+type JSONProblem struct {
+	CheckID  string         `json:"checkID"`
+	Severity check.Severity `json:"severity"`
+	Text     string         `json:"text"`
+
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+
+	Context check.Context `json:"context"`
+
+	Diffs []JSONDiff `json:"diffs"`
+	Fixes []JSONDiff `json:"fixes"`
+}
+
+type JSONDiff struct {
+	Name           string `json:"name"`
+	Current        string `json:"current"`
+	Desired        string `json:"desired"`
+	MissingCurrent bool   `json:"missingCurrent"`
+}
+
+// String is same as presented in plain output (e.g. in templates)
+func (d JSONDiff) String() string {
+	current := d.Current
+	if d.MissingCurrent {
+		current = "(missing)"
+	}
+	return d.Name + ": " + current + " -> " + d.Desired
+}
+
+// JSONError includes errors that caused it (e.g. type-checking errors)
+type JSONError struct {
+	Message string      `json:"message"`
+	Errors  []JSONError `json:"errors,omitempty"`
+}
+
+// jsonReporter writes a single document on Flush
+// or (for ndjson) each reported item on its own line
+type jsonReporter struct {
+	writer io.Writer
+	ndjson bool
+
+	result JSONResult
+	lock   sync.Mutex
+
+	logger *log.Logger
+}
+
+func NewJSONReporter(writer io.Writer, ndjson bool, logger *log.Logger) *jsonReporter {
+	return &jsonReporter{
+		writer: writer,
+		ndjson: ndjson,
+		result: JSONResult{
+			Packages: []JSONPackage{},
+			Problems: []JSONProblem{},
+			Errors:   []JSONError{},
+		},
+		logger: logger,
+	}
+}
+
+func (r *jsonReporter) ReportPackage(pkg *types.Package) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	jsonPkg := JSONPackage{Path: pkg.Path(), Name: pkg.Name()}
+
+	if r.ndjson {
+		r.write(JSONEvent{Type: "package", Package: &jsonPkg})
+	} else {
+		r.result.Packages = append(r.result.Packages, jsonPkg)
+	}
+}
+
+func (r *jsonReporter) ReportFile(*types.Package, *ast.File, string, []string) {}
+
+func (r *jsonReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	jsonProblem := NewJSONProblem(problem)
+
+	if r.ndjson {
+		r.write(JSONEvent{Type: "problem", Problem: &jsonProblem})
+	} else {
+		r.result.Problems = append(r.result.Problems, jsonProblem)
+	}
+}
+
+func (r *jsonReporter) ReportSummary(summary Summary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.ndjson {
+		r.write(JSONEvent{Type: "summary", Summary: &summary})
+	} else {
+		r.result.Summary = &summary
+	}
+}
+
+func (r *jsonReporter) DisplayError(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// Same errors are skipped as in plain output
+	if presentableErr, ok := err.(PresentableError); ok {
+		if !presentableErr.IsPresentable() {
+			return
+		}
+	}
+
+	jsonErr := NewJSONError(err)
+
+	if r.ndjson {
+		r.write(JSONEvent{Type: "error", Error: &jsonErr})
+	} else {
+		r.result.Errors = append(r.result.Errors, jsonErr)
+	}
+}
+
+// Flush writes the document (nothing for ndjson since lines are already written)
+func (r *jsonReporter) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.ndjson {
+		r.write(r.result)
+	}
+
+	return nil
+}
+
+func (r *jsonReporter) write(value interface{}) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		r.logger.Printf("Failed to marshal JSON: %#v", err)
+		return
+	}
+
+	_, err = r.writer.Write(append(bytes, '\n'))
+	if err != nil {
+		r.logger.Printf("Failed to write JSON: %#v", err)
+	}
+}
+
+func NewJSONProblem(problem check.Problem) JSONProblem {
+	jsonProblem := JSONProblem{
+		CheckID:  problem.CheckID,
+		Severity: problem.Severity,
+		Text:     problem.Text,
+		File:     problem.Position.Filename,
+		Line:     problem.Position.Line,
+		Column:   problem.Position.Column,
+		Context:  problem.Context,
+		Diffs:    []JSONDiff{},
+		Fixes:    []JSONDiff{},
+	}
+
+	if problem.Package != nil {
+		jsonProblem.Package = problem.Package.Path()
+	}
+
+	if jsonProblem.Context == nil {
+		jsonProblem.Context = check.Context{}
+	}
+
+	for _, diff := range problem.Diffs {
+		jsonProblem.Diffs = append(jsonProblem.Diffs, newJSONDiff(diff))
+	}
+
+	for _, f := range problem.Fixes {
+		jsonProblem.Fixes = append(jsonProblem.Fixes, newJSONDiff(f))
+	}
+
+	return jsonProblem
+}
+
+func newJSONDiff(diff fix.Diff) JSONDiff {
+	return JSONDiff{
+		Name:           diff.NameStr(),
+		Current:        diff.CurrentStr(),
+		Desired:        diff.DesiredStr(),
+		MissingCurrent: !diff.HasCurrent(),
+	}
+}
+
+func NewJSONError(err error) JSONError {
+	jsonErr := JSONError{Message: err.Error()}
+
+	if errWithUnderlyingErrs, ok := err.(ErrorWithUnderlyingErrors); ok {
+		for _, underlyingErr := range errWithUnderlyingErrs.UnderlyingErrs() {
+			jsonErr.Errors = append(jsonErr.Errors, NewJSONError(underlyingErr))
+		}
+	}
+
+	return jsonErr
+}
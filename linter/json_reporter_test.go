@@ -0,0 +1,162 @@
+package linter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"go/types"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+	"github.com/cppforlife/lint/linter"
+)
+
+func reportJSONResults(t *testing.T, reporter linter.FormatReporter) {
+	pkg := types.NewPackage("github.com/org/pkg", "pkg")
+
+	reporter.ReportPackage(pkg)
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "packageDirName",
+		Severity: check.SeverityWarning,
+		Text:     "Package name should match directory name",
+		Package:  pkg,
+		Position: token.Position{Filename: "/src/pkg/main.go", Line: 1, Column: 1},
+		Context:  check.Context{"dirName": "pkg"},
+		Diffs:    []fix.Diff{fix.SimpleDiff{Name: "doc", Desired: "// Package pkg", MissingCurrent: true}},
+		Fixes: []fix.Fix{
+			fix.NewTextEdits(fix.SimpleDiff{Name: "package", Current: "other", Desired: "pkg"}, nil),
+		},
+	})
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "baseline",
+		Severity: check.SeverityInfo,
+		Text:     "Baseline entry no longer matches any problems",
+		Position: token.Position{Filename: "/src/baseline.json", Line: 3},
+	})
+	reporter.DisplayError(errorWithCauses{"Failed to load github.com/org/broken", []error{errors.New("main.go:1:1: oops")}})
+	reporter.DisplayError(linter.FoundProblemsError{}) // not presentable
+	reporter.ReportSummary(linter.Summary{Packages: 1, Problems: 2, Suppressed: 1})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+}
+
+var (
+	expectedJSONPackage = linter.JSONPackage{Path: "github.com/org/pkg", Name: "pkg"}
+
+	expectedJSONProblems = []linter.JSONProblem{
+		{
+			CheckID:  "packageDirName",
+			Severity: check.SeverityWarning,
+			Text:     "Package name should match directory name",
+			Package:  "github.com/org/pkg",
+			File:     "/src/pkg/main.go",
+			Line:     1,
+			Column:   1,
+			Context:  check.Context{"dirName": "pkg"},
+			Diffs:    []linter.JSONDiff{{Name: "doc", Desired: "// Package pkg", MissingCurrent: true}},
+			Fixes:    []linter.JSONDiff{{Name: "package", Current: "other", Desired: "pkg"}},
+		},
+		{
+			// Problems without a package, context, diffs or fixes have empty values
+			CheckID:  "baseline",
+			Severity: check.SeverityInfo,
+			Text:     "Baseline entry no longer matches any problems",
+			File:     "/src/baseline.json",
+			Line:     3,
+			Context:  check.Context{},
+			Diffs:    []linter.JSONDiff{},
+			Fixes:    []linter.JSONDiff{},
+		},
+	}
+
+	expectedJSONError = linter.JSONError{
+		Message: "Failed to load github.com/org/broken",
+		Errors:  []linter.JSONError{{Message: "main.go:1:1: oops"}},
+	}
+
+	expectedJSONSummary = linter.Summary{Packages: 1, Problems: 2, Suppressed: 1}
+)
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+
+	reportJSONResults(t, linter.NewJSONReporter(&buf, false, log.New(&buf, "", 0)))
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("Expected a single document but was %s", buf.String())
+	}
+
+	var result linter.JSONResult
+
+	err := json.Unmarshal(buf.Bytes(), &result)
+	if err != nil {
+		t.Fatalf("Unmarshal %v", err)
+	}
+
+	expected := linter.JSONResult{
+		Packages: []linter.JSONPackage{expectedJSONPackage},
+		Problems: expectedJSONProblems,
+		Errors:   []linter.JSONError{expectedJSONError},
+		Summary:  &expectedJSONSummary,
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %#v but was %#v", expected, result)
+	}
+}
+
+func TestJSONReporterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := linter.NewJSONReporter(&buf, false, log.New(&buf, "", 0)).Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	// Lists are never null so that consumers do not need to check for them
+	expected := `{"packages":[],"problems":[],"errors":[]}` + "\n"
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %q but was %q", expected, buf.String())
+	}
+}
+
+func TestJSONReporterNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	reportJSONResults(t, linter.NewJSONReporter(&buf, true, log.New(&buf, "", 0)))
+
+	var events []linter.JSONEvent
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event linter.JSONEvent
+
+		err := json.Unmarshal([]byte(line), &event)
+		if err != nil {
+			t.Fatalf("Unmarshal %v", err)
+		}
+
+		events = append(events, event)
+	}
+
+	// Each item is written as soon as it is reported
+	expected := []linter.JSONEvent{
+		{Type: "package", Package: &expectedJSONPackage},
+		{Type: "problem", Problem: &expectedJSONProblems[0]},
+		{Type: "problem", Problem: &expectedJSONProblems[1]},
+		{Type: "error", Error: &expectedJSONError},
+		{Type: "summary", Summary: &expectedJSONSummary},
+	}
+
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Expected %#v but was %#v", expected, events)
+	}
+}
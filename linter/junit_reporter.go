@@ -0,0 +1,254 @@
+package linter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Errors   int               `xml:"errors,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitErrorsSuite includes errors that prevented linting
+// since they do not necessarily belong to a package
+const junitErrorsSuite = "lint"
+
+type junitCaseKey struct {
+	checkID string
+	path    string
+}
+
+type junitPackage struct {
+	keys     []junitCaseKey
+	problems map[junitCaseKey][]check.Problem
+}
+
+// junitReporter writes JUnit XML on Flush; each package is a test suite
+// and each check and file it ran against is a test case that fails
+// if check found problems in the file
+type junitReporter struct {
+	writer io.Writer
+
+	pkgs map[string]*junitPackage
+	errs []error
+	lock sync.Mutex
+
+	logger *log.Logger
+}
+
+func NewJUnitReporter(writer io.Writer, logger *log.Logger) *junitReporter {
+	return &junitReporter{
+		writer: writer,
+		pkgs:   map[string]*junitPackage{},
+		logger: logger,
+	}
+}
+
+func (r *junitReporter) ReportPackage(pkg *types.Package) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.pkg(pkg.Path())
+}
+
+func (r *junitReporter) ReportFile(pkg *types.Package, file *ast.File, path string, checkIDs []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	junitPkg := r.pkg(pkg.Path())
+
+	for _, checkID := range checkIDs {
+		junitPkg.addCase(junitCaseKey{checkID, path})
+	}
+}
+
+func (r *junitReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var pkgPath string
+
+	if problem.Package != nil {
+		pkgPath = problem.Package.Path()
+	}
+
+	key := junitCaseKey{problem.CheckID, problem.Position.Filename}
+
+	// Checks (e.g. ignoreDirective) might not have run against files
+	junitPkg := r.pkg(pkgPath)
+	junitPkg.addCase(key)
+	junitPkg.problems[key] = append(junitPkg.problems[key], problem)
+}
+
+func (r *junitReporter) ReportSummary(summary Summary) {}
+
+func (r *junitReporter) DisplayError(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if presentableErr, ok := err.(PresentableError); ok {
+		if !presentableErr.IsPresentable() {
+			return
+		}
+	}
+
+	r.errs = append(r.errs, err)
+}
+
+// Flush writes test suites ordered by package path
+// and test cases ordered by file and check
+func (r *junitReporter) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result := junitTestSuites{Name: "lint"}
+
+	for pkgPath, junitPkg := range r.pkgs {
+		result.Suites = append(result.Suites, junitPkg.suite(pkgPath))
+	}
+
+	sort.Slice(result.Suites, func(i, j int) bool {
+		return result.Suites[i].Name < result.Suites[j].Name
+	})
+
+	if len(r.errs) > 0 {
+		result.Suites = append(result.Suites, r.errorsSuite())
+	}
+
+	for _, suite := range result.Suites {
+		result.Tests += suite.Tests
+		result.Failures += suite.Failures
+		result.Errors += suite.Errors
+	}
+
+	writeXML(r.writer, result, r.logger)
+
+	return nil
+}
+
+func (r *junitReporter) pkg(path string) *junitPackage {
+	junitPkg, found := r.pkgs[path]
+	if !found {
+		junitPkg = &junitPackage{problems: map[junitCaseKey][]check.Problem{}}
+		r.pkgs[path] = junitPkg
+	}
+	return junitPkg
+}
+
+func (r *junitReporter) errorsSuite() *junitTestSuite {
+	suite := &junitTestSuite{Name: junitErrorsSuite}
+
+	for i, err := range r.errs {
+		texts := []string{err.Error()}
+
+		if errWithUnderlyingErrs, ok := err.(ErrorWithUnderlyingErrors); ok {
+			for _, underlyingErr := range errWithUnderlyingErrs.UnderlyingErrs() {
+				texts = append(texts, "- "+underlyingErr.Error())
+			}
+		}
+
+		suite.Cases = append(suite.Cases, &junitTestCase{
+			Name:      fmt.Sprintf("error %d", i+1),
+			ClassName: junitErrorsSuite,
+			Error: &junitMessage{
+				Message: err.Error(),
+				Type:    fmt.Sprintf("%T", err),
+				Text:    strings.Join(texts, "\n"),
+			},
+		})
+	}
+
+	suite.Tests = len(suite.Cases)
+	suite.Errors = len(suite.Cases)
+
+	return suite
+}
+
+func (p *junitPackage) addCase(key junitCaseKey) {
+	if _, found := p.problems[key]; !found {
+		p.problems[key] = nil
+		p.keys = append(p.keys, key)
+	}
+}
+
+func (p *junitPackage) suite(pkgPath string) *junitTestSuite {
+	suite := &junitTestSuite{Name: pkgPath}
+
+	keys := append([]junitCaseKey{}, p.keys...)
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		return keys[i].checkID < keys[j].checkID
+	})
+
+	for _, key := range keys {
+		testCase := &junitTestCase{
+			Name:      key.checkID + " " + filepath.Base(key.path),
+			ClassName: pkgPath,
+			File:      key.path,
+		}
+
+		if problems := p.problems[key]; len(problems) > 0 {
+			var lines []string
+
+			for _, problem := range problems {
+				lines = append(lines, fmt.Sprintf(
+					"%s:%d:%d: %s: %s", problem.Position.Filename,
+					problem.Position.Line, problem.Position.Column, problem.Severity, problem.Text))
+			}
+
+			testCase.Failure = &junitMessage{
+				Message: pluralize(len(problems), "problem") + " found",
+				Type:    key.checkID,
+				Text:    strings.Join(lines, "\n"),
+			}
+
+			suite.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.Tests = len(suite.Cases)
+
+	return suite
+}
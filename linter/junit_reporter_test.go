@@ -0,0 +1,76 @@
+package linter_test
+
+import (
+	"bytes"
+	"errors"
+	"go/token"
+	"go/types"
+	"log"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	var buf bytes.Buffer
+
+	reporter := linter.NewJUnitReporter(&buf, log.New(&buf, "", 0))
+
+	pkg := types.NewPackage("github.com/org/pkg", "pkg")
+	emptyPkg := types.NewPackage("github.com/org/empty", "empty")
+
+	reporter.ReportPackage(pkg)
+	reporter.ReportFile(pkg, nil, "/src/pkg/main.go", []string{"packageDirName", "errorAssignment"})
+	reporter.ReportFile(pkg, nil, "/src/pkg/close.go", []string{"packageDirName", "errorAssignment"})
+	reporter.ReportPackage(emptyPkg)
+
+	for _, problem := range []check.Problem{
+		{CheckID: "errorAssignment", Severity: check.SeverityError, Text: "Return value of type error should be used",
+			Package: pkg, Position: token.Position{Filename: "/src/pkg/close.go", Line: 7, Column: 2}},
+		{CheckID: "errorAssignment", Severity: check.SeverityError, Text: "Return value of type error should be assigned and used",
+			Package: pkg, Position: token.Position{Filename: "/src/pkg/close.go", Line: 9, Column: 2}},
+		// Check did not run against the file
+		{CheckID: "ignoreDirective", Severity: check.SeverityWarning, Text: "Ignore directive does not suppress any problems",
+			Package: pkg, Position: token.Position{Filename: "/src/pkg/main.go", Line: 5, Column: 1}},
+	} {
+		reporter.ReportProblem(problem)
+	}
+
+	reporter.DisplayError(errorWithCauses{"Failed to load github.com/org/broken", []error{errors.New("main.go:1:1: oops")}})
+	reporter.DisplayError(linter.FoundProblemsError{}) // not presentable
+	reporter.ReportSummary(linter.Summary{Packages: 2, Problems: 3})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	// Suites are ordered by package path and cases by file and check;
+	// problems of the same check and file fail a single case
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="lint" tests="6" failures="2" errors="1">
+  <testsuite name="github.com/org/empty" tests="0" failures="0" errors="0"></testsuite>
+  <testsuite name="github.com/org/pkg" tests="5" failures="2" errors="0">
+    <testcase name="errorAssignment close.go" classname="github.com/org/pkg" file="/src/pkg/close.go">
+      <failure message="2 problems found" type="errorAssignment">/src/pkg/close.go:7:2: error: Return value of type error should be used&#xA;/src/pkg/close.go:9:2: error: Return value of type error should be assigned and used</failure>
+    </testcase>
+    <testcase name="packageDirName close.go" classname="github.com/org/pkg" file="/src/pkg/close.go"></testcase>
+    <testcase name="errorAssignment main.go" classname="github.com/org/pkg" file="/src/pkg/main.go"></testcase>
+    <testcase name="ignoreDirective main.go" classname="github.com/org/pkg" file="/src/pkg/main.go">
+      <failure message="1 problem found" type="ignoreDirective">/src/pkg/main.go:5:1: warning: Ignore directive does not suppress any problems</failure>
+    </testcase>
+    <testcase name="packageDirName main.go" classname="github.com/org/pkg" file="/src/pkg/main.go"></testcase>
+  </testsuite>
+  <testsuite name="lint" tests="1" failures="0" errors="1">
+    <testcase name="error 1" classname="lint">
+      <error message="Failed to load github.com/org/broken" type="linter_test.errorWithCauses">Failed to load github.com/org/broken&#xA;- main.go:1:1: oops</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %s but was %s", expected, buf.String())
+	}
+}
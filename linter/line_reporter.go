@@ -0,0 +1,80 @@
+package linter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// lineReporter prints each problem on its own line in the same way
+// as compilers do (path:line:col: message) so that editors could
+// jump to problem locations; errors are displayed by ui
+type lineReporter struct {
+	writer io.Writer
+	ui     UI
+
+	// Paths are relative to this directory unless empty
+	relDir string
+
+	lock sync.Mutex
+
+	logger *log.Logger
+}
+
+func NewLineReporter(writer io.Writer, ui UI, relDir string, logger *log.Logger) *lineReporter {
+	return &lineReporter{
+		writer: writer,
+		ui:     ui,
+		relDir: relDir,
+		logger: logger,
+	}
+}
+
+func (r *lineReporter) ReportPackage(*types.Package) {}
+
+func (r *lineReporter) ReportFile(*types.Package, *ast.File, string, []string) {}
+
+func (r *lineReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, err := fmt.Fprintf(
+		r.writer, "%s:%d:%d: [%s] %s\n",
+		r.path(problem.Position.Filename),
+		problem.Position.Line,
+		problem.Position.Column,
+		problem.CheckID,
+		problem.Text,
+	)
+	if err != nil {
+		r.logger.Printf("Failed to print problem: %#v", err)
+	}
+}
+
+func (r *lineReporter) ReportSummary(Summary) {}
+
+func (r *lineReporter) DisplayError(err error) {
+	r.ui.DisplayError(err)
+}
+
+// Flush does nothing since problems are printed as soon as they are reported
+func (r *lineReporter) Flush() error { return nil }
+
+func (r *lineReporter) path(path string) string {
+	if len(r.relDir) == 0 {
+		return path
+	}
+
+	relPath, err := filepath.Rel(r.relDir, path)
+	if err != nil {
+		return path
+	}
+
+	return relPath
+}
@@ -0,0 +1,64 @@
+package linter_test
+
+import (
+	"bytes"
+	"errors"
+	"go/token"
+	"log"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+func reportLineResults(t *testing.T, reporter linter.FormatReporter) {
+	for _, problem := range []check.Problem{
+		{CheckID: "packageDirName", Severity: check.SeverityWarning, Text: "Package name should match directory name",
+			Position: token.Position{Filename: "/src/pkg/main.go", Line: 1, Column: 1}},
+		{CheckID: "errorAssignment", Severity: check.SeverityError, Text: "Return value of type error should be used",
+			Position: token.Position{Filename: "/other/main.go", Line: 7, Column: 2}},
+	} {
+		reporter.ReportProblem(problem)
+	}
+
+	reporter.DisplayError(errorWithCauses{"Failed to load github.com/org/broken", []error{errors.New("main.go:1:1: oops")}})
+	reporter.ReportSummary(linter.Summary{Packages: 1, Problems: 2})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+}
+
+func TestLineReporter(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+
+	reportLineResults(t, linter.NewLineReporter(&buf, linter.NewPlainUI(&errBuf, log.New(&errBuf, "", 0)), "/src", log.New(&buf, "", 0)))
+
+	// Problems are printed as soon as they are reported (without a summary)
+	expected := "pkg/main.go:1:1: [packageDirName] Package name should match directory name\n" +
+		"../other/main.go:7:2: [errorAssignment] Return value of type error should be used\n"
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %q but was %q", expected, buf.String())
+	}
+
+	expectedErrs := "\n[error] Failed to load github.com/org/broken\n        - main.go:1:1: oops\n"
+
+	if errBuf.String() != expectedErrs {
+		t.Fatalf("Expected errors %q but was %q", expectedErrs, errBuf.String())
+	}
+}
+
+func TestLineReporterAbsolutePaths(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+
+	reportLineResults(t, linter.NewLineReporter(&buf, linter.NewPlainUI(&errBuf, log.New(&errBuf, "", 0)), "", log.New(&buf, "", 0)))
+
+	expected := "/src/pkg/main.go:1:1: [packageDirName] Package name should match directory name\n" +
+		"/other/main.go:7:2: [errorAssignment] Return value of type error should be used\n"
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %q but was %q", expected, buf.String())
+	}
+}
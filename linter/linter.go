@@ -3,87 +3,476 @@ package linter
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"log"
 
-	goloader "code.google.com/p/go.tools/go/loader"
-
 	"github.com/cppforlife/lint/check"
 )
 
 type Linter interface {
-	Run(program *goloader.Program) ([]check.Problem, error)
+	Run(program *check.Program) ([]check.Problem, error)
+	Finish() ([]check.Problem, error)
 }
 
 type FoundProblemsError struct {
-	count int
+	counts map[check.Severity]int
+
+	// Number of problems created from package errors
+	packageErrors int
+}
+
+func newFoundProblemsError(problems []check.Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	e := FoundProblemsError{counts: map[check.Severity]int{}}
+
+	for _, problem := range problems {
+		e.counts[problem.Severity]++
+
+		if problem.CheckID == PackageErrorsCheckID {
+			e.packageErrors++
+		}
+	}
+
+	return e
 }
 
 func (e FoundProblemsError) Error() string {
-	errorText := "error"
-	if e.count == 0 || e.count > 1 {
-		errorText += "s"
+	count := e.Count(check.SeverityInfo)
+
+	problemText := "problem"
+	if count == 0 || count > 1 {
+		problemText += "s"
 	}
-	return fmt.Sprintf("%d %s found", e.count, errorText)
+	return fmt.Sprintf("%d %s found", count, problemText)
 }
 
 func (e FoundProblemsError) IsPresentable() bool { return false }
 
+// Count returns number of problems with at least given severity
+func (e FoundProblemsError) Count(severity check.Severity) int {
+	var count int
+
+	for s, c := range e.counts {
+		if s.AtLeast(severity) {
+			count += c
+		}
+	}
+
+	return count
+}
+
+// Add combines problems found in multiple programs
+func (e FoundProblemsError) Add(other FoundProblemsError) FoundProblemsError {
+	sum := FoundProblemsError{
+		counts:        map[check.Severity]int{},
+		packageErrors: e.packageErrors + other.packageErrors,
+	}
+
+	for _, counts := range []map[check.Severity]int{e.counts, other.counts} {
+		for s, c := range counts {
+			sum.counts[s] += c
+		}
+	}
+
+	return sum
+}
+
+// PackageErrorsCheckID is used for problems created from
+// errors in packages loaded with partial type information
+const PackageErrorsCheckID = "packageErrors"
+
+// Severities of problems found by the linter itself
+const (
+	packageErrorsSeverity   = check.SeverityError
+	ignoreDirectiveSeverity = check.SeverityWarning
+	baselineSeverity        = check.SeverityWarning
+)
+
+// internalDefinitions describe problems found by the linter itself
+// (e.g. to be presented together with registered checks)
+var internalDefinitions = []check.Definition{
+	{ID: PackageErrorsCheckID, Description: "Packages should load without errors", Severity: packageErrorsSeverity},
+	{ID: IgnoreDirectiveCheckID, Description: "Suppression comments should be valid and used", Severity: ignoreDirectiveSeverity},
+	{ID: BaselineCheckID, Description: "Baseline entries should match problems", Severity: baselineSeverity},
+}
+
+// definitions returns registered checks followed by internal ones
+func definitions(registry *check.Registry) []check.Definition {
+	defs := append([]check.Definition{}, registry.Definitions()...)
+	return append(defs, internalDefinitions...)
+}
+
 type linter struct {
 	reporter Reporter
-	logger   *log.Logger
+
+	// Enabled checks and their options are determined per package directory
+	registry  *check.Registry
+	config    Config
+	selection check.Selection
+
+	// Suppression comments that did not suppress
+	// any problems are reported once all programs are linted
+	reportUnusedIgnores bool
+
+	// Problems found in baseline are not reported (nil if not used);
+	// all problems are recorded in newBaseline (nil if not written)
+	baseline    *Baseline
+	newBaseline *Baseline
+
+	// Maximum number of checks run concurrently for a program
+	concurrency int
+
+	// Shared between concurrently linted programs
+	reported *problemSet
+
+	logger *log.Logger
 }
 
-func NewLinter(reporter Reporter, logger *log.Logger) linter {
-	return linter{reporter, logger}
+type identifiedCheck struct {
+	checkID  string
+	severity check.Severity
+
+	// Position of the node, file or package check was found for
+	position token.Position
+
+	check check.Check
+}
+
+func NewLinter(
+	reporter Reporter,
+	registry *check.Registry,
+	config Config,
+	selection check.Selection,
+	reportUnusedIgnores bool,
+	baseline *Baseline,
+	newBaseline *Baseline,
+	concurrency int,
+	logger *log.Logger,
+) linter {
+	return linter{
+		reporter, registry, config, selection, reportUnusedIgnores,
+		baseline, newBaseline, concurrency, newProblemSet(), logger,
+	}
 }
 
 // Run runs list of checks against a loaded program
-// and returns list of problems found
-func (l linter) Run(program *goloader.Program) ([]check.Problem, error) {
-	var checks []check.Check
+// and returns list of problems found; problems found by
+// other checks are reported even if some checks fail
+func (l linter) Run(program *check.Program) ([]check.Problem, error) {
+	if l.concurrency < 1 {
+		return nil, fmt.Errorf("Concurrency must be at least 1 but was %d", l.concurrency)
+	}
+
+	var checks []identifiedCheck
 	var problems []check.Problem
+	var directives ignoreDirectives
+	var errs []error
+
+	// Used to determine enclosing functions of problems
+	files := map[string]*ast.File{}
 
-	finders := []check.Finder{
-		check.NewErrorAssignmentsFinder(),
-		check.NewTestPackageSuffixFinder(),
-		check.NewPackageDirNameFinder(),
-		check.NewGingkoSuiteTestFileFinder(),
+	// Program finders are enabled per package but run once per program
+	var programFinders []CheckFinder
+	programFinderIDs := map[string]bool{}
+	programHasErrors := false
+
+	for _, pkg := range program.InitialPackages() {
+		programHasErrors = programHasErrors || pkg.HasErrors()
 	}
 
 	numPkgs, numFiles := 0, 0
 
 	for _, pkg := range program.InitialPackages() {
 		numPkgs++
-		l.reporter.ReportPackage(pkg.Pkg)
+
+		finders, err := l.config.Finders(l.registry, pkg.Dir, l.selection)
+		if err != nil {
+			return problems, err
+		}
+
+		// Facts of imported packages are needed by finders and checks
+		errs = append(errs, l.computeFacts(pkg, program.Fset)...)
+
+		// Same package might have been already linted for another platform
+		if l.reported.AddPackage(pkg.Pkg) {
+			l.reporter.ReportPackage(pkg.Pkg)
+		}
 
 		for _, file := range pkg.Files {
 			numFiles++
-			l.reporter.ReportFile(pkg.Pkg, file)
+			files[program.Fset.Position(file.Package).Filename] = file
+		}
+
+		// Errors are only present when partial loading is allowed
+		problems = append(problems, l.packageErrorProblems(pkg, program.Fset)...)
+
+		pkgDirectives, directiveProblems := newIgnoreDirectives(pkg, program.Fset, l.registry)
+		directives = append(directives, pkgDirectives...)
+		problems = append(problems, directiveProblems...)
+
+		ranCheckIDs := map[string]bool{PackageErrorsCheckID: true}
+
+		for _, finder := range finders {
+			hasErrors := pkg.HasErrors()
+			if _, ok := finder.AnyFinder.(check.ProgramFinder); ok {
+				hasErrors = programHasErrors
+			}
+
+			// Incomplete type information would result in bogus problems
+			ranCheckIDs[finder.ID] = !hasErrors || !finder.RequiresTypes()
+		}
+
+		if l.reportUnusedIgnores {
+			l.reported.AddIgnores(pkgDirectives.Ran(ranCheckIDs))
+		}
+
+		var fileCheckIDs []string
+
+		for _, finder := range finders {
+			if ranCheckIDs[finder.ID] {
+				fileCheckIDs = append(fileCheckIDs, finder.ID)
+			}
+		}
+
+		for _, file := range pkg.Files {
+			l.reporter.ReportFile(pkg.Pkg, file, program.Fset.Position(file.Package).Filename, fileCheckIDs)
+		}
+
+		// Node finders share a single traversal of each file
+		var nodeFinders []CheckFinder
+
+		for _, finder := range finders {
+			if !ranCheckIDs[finder.ID] {
+				l.logger.Printf("Skipping %s for %s with errors\n", finder.ID, pkg.Pkg.Path())
+				continue
+			}
+
+			switch f := finder.AnyFinder.(type) {
+			case check.NodeFinder:
+				nodeFinders = append(nodeFinders, finder)
+
+			case check.Finder:
+				for _, file := range pkg.Files {
+					file := file
+					astWalker := func(e check.AstNodeEvaler) { ast.Inspect(file, e) }
 
-			astWalker := func(e check.AstNodeEvaler) { ast.Inspect(file, e) }
+					found, err := findChecks(finder, program.Fset.Position(file.Package), func() []check.Check {
+						return f.FindInAST(astWalker, pkg, file, program.Fset)
+					})
+					checks = append(checks, found...)
+					errs = appendErr(errs, err)
+				}
 
-			for _, finder := range finders {
-				checks = append(checks, finder.FindInAST(astWalker, pkg, file, program.Fset)...)
+			case check.PackageFinder:
+				found, err := findChecks(finder, packagePosition(pkg, program.Fset), func() []check.Check {
+					return f.FindInPackage(pkg, program.Fset)
+				})
+				checks = append(checks, found...)
+				errs = appendErr(errs, err)
+
+			case check.ProgramFinder:
+				if !programFinderIDs[finder.ID] {
+					programFinderIDs[finder.ID] = true
+					programFinders = append(programFinders, finder)
+				}
 			}
 		}
+
+		dispatcher := newASTDispatcher(nodeFinders)
+
+		for _, file := range pkg.Files {
+			found, walkErrs := dispatcher.Walk(pkg, file, program.Fset)
+			checks = append(checks, found...)
+			errs = append(errs, walkErrs...)
+		}
 	}
 
-	for _, check := range checks {
-		prs, err := check.Check()
-		if err != nil {
-			return problems, err
+	for _, finder := range programFinders {
+		var position token.Position
+
+		if pkgs := program.InitialPackages(); len(pkgs) > 0 {
+			position = packagePosition(pkgs[0], program.Fset)
 		}
 
-		problems = append(problems, prs...)
+		found, err := findChecks(finder, position, func() []check.Check {
+			return finder.AnyFinder.(check.ProgramFinder).FindInProgram(program)
+		})
+		checks = append(checks, found...)
+		errs = appendErr(errs, err)
 	}
 
+	checkProblems, checkErrs := l.runChecks(checks)
+	problems = append(problems, checkProblems...)
+	errs = append(errs, checkErrs...)
+
+	// Files shared between platforms produce same problems
+	problems = l.reported.AddProblems(problems)
+
+	problems, usedDirectives := directives.Filter(problems)
+	l.reported.AddSuppressed(usedDirectives)
+
+	problems = l.filterBaselined(problems, files, program.Fset)
+
 	for _, problem := range problems {
 		l.reporter.ReportProblem(problem)
 	}
 
-	if len(problems) > 0 {
-		return problems, FoundProblemsError{count: len(problems)}
+	// Errors of all failed finders and checks are reported together
+	if len(errs) > 0 {
+		return problems, CheckErrors{errs}
+	}
+
+	return problems, newFoundProblemsError(problems)
+}
+
+// Finish reports problems that can only be determined
+// once all programs are linted followed by a summary
+func (l linter) Finish() ([]check.Problem, error) {
+	var problems []check.Problem
+
+	if l.reportUnusedIgnores {
+		for _, d := range l.reported.UnusedIgnores() {
+			problems = append(problems, d.UnusedProblem())
+		}
+	}
+
+	if l.baseline != nil {
+		problems = append(problems, l.baseline.StaleProblems(l.reported.Packages())...)
+	}
+
+	problems = l.reported.AddProblems(problems)
+
+	for _, problem := range problems {
+		l.reporter.ReportProblem(problem)
+	}
+
+	l.reporter.ReportSummary(l.reported.Summary())
+
+	return problems, newFoundProblemsError(problems)
+}
+
+// findChecks identifies checks found by a finder;
+// finder panic is returned as an internal check error
+func findChecks(finder CheckFinder, position token.Position, find func() []check.Check) (checks []identifiedCheck, err error) {
+	defer recoverCheck(finder.ID, position, &err)
+
+	for _, c := range find() {
+		checks = append(checks, identifiedCheck{finder.ID, finder.Severity, position, c})
+	}
+
+	return checks, nil
+}
+
+// computeFacts computes facts of the package and its imports
+// using all finders configured for each package directory
+// (including disabled ones since enabled checks might rely on them)
+func (l linter) computeFacts(pkg *check.PackageInfo, fset *token.FileSet) []error {
+	if pkg.Facts == nil {
+		return nil
+	}
+
+	return pkg.Facts.Compute(pkg, fset, func(factsPkg *check.PackageInfo) []error {
+		finders, err := l.config.Finders(l.registry, factsPkg.Dir, check.Selection{Enable: []string{"all"}})
+		if err != nil {
+			return []error{err}
+		}
+
+		var errs []error
+
+		for _, finder := range finders {
+			factFinder, ok := finder.AnyFinder.(check.FactFinder)
+			if !ok || (factsPkg.HasErrors() && finder.RequiresTypes()) {
+				continue
+			}
+
+			errs = appendErr(errs, computeFinderFacts(finder.ID, factFinder, factsPkg, fset))
+		}
+
+		return errs
+	})
+}
+
+// computeFinderFacts returns finder panic as an internal check error
+func computeFinderFacts(checkID string, finder check.FactFinder, pkg *check.PackageInfo, fset *token.FileSet) (err error) {
+	defer recoverCheck(checkID, packagePosition(pkg, fset), &err)
+
+	err = finder.ComputeFacts(pkg, fset)
+	if err != nil {
+		return CheckError{checkID, err}
+	}
+
+	return nil
+}
+
+// packagePosition is the position of the first file package clause
+func packagePosition(pkg *check.PackageInfo, fset *token.FileSet) token.Position {
+	if len(pkg.Files) == 0 {
+		return token.Position{Filename: pkg.Dir}
+	}
+
+	return fset.Position(pkg.Files[0].Package)
+}
+
+func appendErr(errs []error, err error) []error {
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// filterBaselined records problems for a new baseline
+// and removes those found in the current baseline
+func (l linter) filterBaselined(problems []check.Problem, files map[string]*ast.File, fset *token.FileSet) []check.Problem {
+	if l.baseline == nil && l.newBaseline == nil {
+		return problems
+	}
+
+	var fingerprints []Fingerprint
+
+	for _, problem := range problems {
+		fingerprints = append(fingerprints, NewFingerprint(problem, files, fset))
+	}
+
+	if l.newBaseline != nil {
+		l.newBaseline.Record(fingerprints)
+	}
+
+	if l.baseline != nil {
+		var baselined int
+
+		problems, baselined = l.baseline.Filter(problems, fingerprints)
+		l.reported.AddBaselined(baselined)
+	}
+
+	return problems
+}
+
+// packageErrorProblems presents load and type-checking errors as problems;
+// errors without position are attributed to the first file of the package
+func (l linter) packageErrorProblems(pkg *check.PackageInfo, fset *token.FileSet) []check.Problem {
+	var problems []check.Problem
+
+	for _, pkgErr := range pkg.Errors {
+		position := pkgErr.Position
+
+		if !position.IsValid() && len(pkg.Files) > 0 {
+			position = fset.Position(pkg.Files[0].Package)
+		}
+
+		problems = append(problems, check.Problem{
+			CheckID:  PackageErrorsCheckID,
+			Severity: packageErrorsSeverity,
+			Text:     "Package should load without errors",
+			Package:  pkg.Pkg,
+			Position: position,
+			Context: check.Context{
+				"error": pkgErr.Msg,
+			},
+		})
 	}
 
-	return problems, nil
+	return problems
 }
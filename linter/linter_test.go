@@ -0,0 +1,187 @@
+package linter_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+// fileFinder finds same checks in each file
+type fileFinder struct {
+	checks []check.Check
+}
+
+func (f fileFinder) RequiresTypes() bool { return false }
+
+func (f fileFinder) FindInAST(check.AstWalker, *check.PackageInfo, *ast.File, *token.FileSet) []check.Check {
+	return f.checks
+}
+
+// staticCheck returns given problems and error
+type staticCheck struct {
+	problems []check.Problem
+	err      error
+}
+
+func (c staticCheck) Check() ([]check.Problem, error) { return c.problems, c.err }
+
+type callsFinder struct{}
+
+func (callsFinder) RequiresTypes() bool { return false }
+
+func (callsFinder) NodeTypes() []ast.Node { return []ast.Node{(*ast.CallExpr)(nil)} }
+
+// FindInNode panics on calls of boom (e.g. as a check would on unexpected code)
+func (callsFinder) FindInNode(n ast.Node, pkg *check.PackageInfo, file *ast.File, fset *token.FileSet) ([]check.Check, bool) {
+	name := n.(*ast.CallExpr).Fun.(*ast.Ident).Name
+	if name == "boom" {
+		panic("unexpected call")
+	}
+
+	problem := check.Problem{Text: "call " + name, Package: pkg.Pkg, Position: fset.Position(n.Pos())}
+
+	return []check.Check{staticCheck{[]check.Problem{problem}, nil}}, true
+}
+
+type pkgFinder struct{}
+
+func (pkgFinder) RequiresTypes() bool { return false }
+
+func (pkgFinder) FindInPackage(pkg *check.PackageInfo, fset *token.FileSet) []check.Check {
+	problem := check.Problem{Text: "package " + pkg.Pkg.Name(), Package: pkg.Pkg, Position: fset.Position(pkg.Files[0].Package)}
+
+	return []check.Check{staticCheck{[]check.Problem{problem}, nil}}
+}
+
+// errorWithCauses is presented together with errors that caused it
+type errorWithCauses struct {
+	msg    string
+	causes []error
+}
+
+func (e errorWithCauses) Error() string { return e.msg }
+
+func (e errorWithCauses) UnderlyingErrs() []error { return e.causes }
+
+type discardReporter struct{}
+
+func (discardReporter) ReportPackage(*types.Package) {}
+
+func (discardReporter) ReportFile(*types.Package, *ast.File, string, []string) {}
+
+func (discardReporter) ReportProblem(check.Problem) {}
+
+func (discardReporter) ReportSummary(linter.Summary) {}
+
+// finderDef defines a check enabled by default
+func finderDef(id string, severity check.Severity, finder check.AnyFinder) check.Definition {
+	return check.Definition{
+		ID:               id,
+		EnabledByDefault: true,
+		Severity:         severity,
+		NewFinder:        func(check.Options) (check.AnyFinder, error) { return finder, nil },
+	}
+}
+
+// reporterTestDefs are checks presented by reporters
+var reporterTestDefs = []check.Definition{
+	{ID: "packageDirName", Description: "Package name should match directory name", Severity: check.SeverityWarning, EnabledByDefault: true},
+	{ID: "errorAssignment", Description: "Errors should be assigned and used", Severity: check.SeverityError},
+}
+
+func newTestRegistry(t testing.TB, defs ...check.Definition) *check.Registry {
+	registry := &check.Registry{}
+
+	for _, def := range defs {
+		err := registry.Register(def)
+		if err != nil {
+			t.Fatalf("Register %v", err)
+		}
+	}
+
+	return registry
+}
+
+func newTestLinter(t testing.TB, defs ...check.Definition) linter.Linter {
+	return linter.NewLinter(discardReporter{}, newTestRegistry(t, defs...), linter.Config{}, check.Selection{},
+		false, nil, nil, 2, log.New(ioutil.Discard, "", 0))
+}
+
+func TestLinterRunPanickingFinder(t *testing.T) {
+	fset := token.NewFileSet()
+
+	program := check.NewProgram(fset, []*check.PackageInfo{
+		parseTestPackage(t, fset, "a", "package a\nfunc f() {\n\tfirst()\n\tboom(skipped())\n\tlast()\n}\n"),
+		parseTestPackage(t, fset, "b", "package b\nfunc f() {\n\tother()\n}\n"),
+	})
+
+	l := newTestLinter(t,
+		finderDef("calls", check.SeverityWarning, callsFinder{}),
+		finderDef("pkgs", check.SeverityWarning, pkgFinder{}),
+	)
+
+	problems, err := l.Run(program)
+
+	checkErrs, ok := err.(linter.CheckErrors)
+	if !ok {
+		t.Fatalf("Expected check errors but was %#v", err)
+	}
+
+	if len(checkErrs.UnderlyingErrs()) != 1 {
+		t.Fatalf("Expected single check error but was %#v", checkErrs.UnderlyingErrs())
+	}
+
+	internalErr, ok := checkErrs.UnderlyingErrs()[0].(linter.InternalCheckError)
+	if !ok {
+		t.Fatalf("Expected internal check error but was %#v", checkErrs.UnderlyingErrs()[0])
+	}
+
+	// Position of the boom call is included together with the stack trace
+	if !strings.HasPrefix(internalErr.Error(), "Internal check error in 'calls' at /src/a/main.go:4:2: unexpected call\n") {
+		t.Fatalf("Expected internal error of calls at boom call but was %s", internalErr.Error())
+	}
+
+	var texts []string
+
+	for _, problem := range problems {
+		texts = append(texts, problem.Position.String()+" "+problem.Text)
+	}
+
+	sort.Strings(texts)
+
+	// Arguments of the panicked call are skipped; other nodes, checks and packages are not
+	expected := []string{
+		"/src/a/main.go:1:1 package a",
+		"/src/a/main.go:3:2 call first",
+		"/src/a/main.go:5:2 call last",
+		"/src/b/main.go:1:1 package b",
+		"/src/b/main.go:3:2 call other",
+	}
+
+	if !reflect.DeepEqual(texts, expected) {
+		t.Fatalf("Expected problems %#v but was %#v", expected, texts)
+	}
+}
+
+func parseTestPackage(t testing.TB, fset *token.FileSet, name, src string) *check.PackageInfo {
+	file, err := parser.ParseFile(fset, "/src/"+name+"/main.go", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile %v", err)
+	}
+
+	return &check.PackageInfo{
+		Pkg:   types.NewPackage("github.com/org/"+name, name),
+		Dir:   "/src/" + name,
+		Files: []*ast.File{file},
+	}
+}
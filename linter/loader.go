@@ -2,19 +2,23 @@ package linter
 
 import (
 	"fmt"
-	"go/parser"
+	"go/ast"
+	"go/build"
 	"go/token"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
-	goloader "code.google.com/p/go.tools/go/loader"
-	gotypes "code.google.com/p/go.tools/go/types"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/cppforlife/lint/check"
 )
 
 type Loader interface {
-	Programs() (<-chan *goloader.Program, <-chan error, error)
+	Programs() (<-chan *check.Program, <-chan error, error)
 }
 
 type LoadError struct {
@@ -32,8 +36,17 @@ func (e LoadError) UnderlyingErrs() []error {
 }
 
 type loader struct {
-	goSrc  string
+	dir    string // packages are resolved relative to this directory
 	args   []string
+	filter PathFilter
+	build  BuildConfig
+
+	// Packages with errors are loaded with partial type information
+	allowErrors bool
+
+	// Maximum number of concurrent loads
+	concurrency int
+
 	logger *log.Logger
 }
 
@@ -48,76 +61,105 @@ func (dc *dirContents) AddPath(path string) {
 	}
 }
 
-func (dc *dirContents) PackageName(goSrc string) string {
-	return strings.TrimPrefix(dc.Path, goSrc+string(filepath.Separator))
-}
+// loadMode includes everything that checks need
+// from initial packages; dependencies are type-checked
+// from export data found in the build cache
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedForTest |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedTypes |
+	packages.NeedTypesSizes |
+	packages.NeedSyntax |
+	packages.NeedTypesInfo
+
+// NewLoaderFromArgs constructs a loader that resolves packages
+// from dir which could be inside of a Go module or GOPATH
+func NewLoaderFromArgs(
+	dir string,
+	args []string,
+	filter PathFilter,
+	build BuildConfig,
+	allowErrors bool,
+	concurrency int,
+	logger *log.Logger,
+) (loader, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return loader{}, fmt.Errorf("dir cannot be determined %#v", err)
+	}
+
+	if concurrency < 1 {
+		return loader{}, fmt.Errorf("Concurrency must be at least 1 but was %d", concurrency)
+	}
 
-func NewLoaderFromArgs(goPath string, args []string, logger *log.Logger) (loader, error) {
-	absGoSrc, err := filepath.Abs(filepath.Join(goPath, "src"))
+	err = filter.validate()
 	if err != nil {
-		return loader{}, fmt.Errorf("gosrc cannot be determined %#v", err)
+		return loader{}, fmt.Errorf("Invalid path pattern %#v", err)
 	}
 
-	if len(args) != 1 {
-		return loader{}, fmt.Errorf("Must provide exactly one package to load")
+	// Same as `go list` default to the current directory
+	if len(args) == 0 {
+		args = []string{"."}
 	}
 
 	return loader{
-		goSrc:  absGoSrc,
+		dir:    absDir,
 		args:   args,
+		filter: filter,
+		build:  build,
+
+		allowErrors: allowErrors,
+		concurrency: concurrency,
+
 		logger: logger,
 	}, nil
 }
 
-func (l loader) Programs() (<-chan *goloader.Program, <-chan error, error) {
-	if l.goSrc == "" {
-		return nil, nil, fmt.Errorf("gopath is missing")
-	}
-
-	dir, err := filepath.Abs(filepath.Join(l.goSrc, l.args[0]))
-	if err != nil {
-		return nil, nil, fmt.Errorf("Building path %#v", err)
-	}
-
-	pathsByDir, err := l.groupPathsByDir(dir)
+// Programs loads matched directories that belong to the same module
+// in batches of at most concurrency directories so that dependencies are
+// parsed and type-checked only once per batch while only few batches are
+// kept in memory. Each directory is still returned as a separate program.
+func (l loader) Programs() (<-chan *check.Program, <-chan error, error) {
+	pathsByDir, err := l.expandArgs()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	maxResults := len(pathsByDir)
+	dirsByModule := l.groupDirsByModule(pathsByDir)
 
-	// Keeps all loaded programs
-	programsCh := make(chan *goloader.Program, maxResults)
+	// Each directory results in at most one error
+	// (and each module in at most one error when sorting it)
+	maxResults := (len(pathsByDir) + len(dirsByModule)) * len(l.build.Platforms)
+
+	// Each module is loaded separately for each platform
+	numModules := len(dirsByModule) * len(l.build.Platforms)
+
+	// Loading waits for loaded programs to be picked up
+	programsCh := make(chan *check.Program)
 
 	// Keeps errors from loading programs
 	errsCh := make(chan error, maxResults)
 
-	// Populated by loading program goroutines
+	// Populated by loading module goroutines
 	endCh := make(chan struct{})
 
-	// Load all packages in all non-empty directories
-	for _, dc := range pathsByDir {
-		go func(dc *dirContents) {
-			if len(dc.Paths) > 0 {
-				l.logger.Printf("Loading directory %s with %d file(s)\n", dc.Path, len(dc.Paths))
-
-				program, err := l.loadProgram(dc.PackageName(l.goSrc))
-				if err != nil {
-					errsCh <- err
-				} else {
-					programsCh <- program
-				}
-			} else {
-				l.logger.Printf("Skipping %s with 0 files\n", dc.Path)
-			}
+	// Limits number of concurrent loads
+	slotsCh := make(chan struct{}, l.concurrency)
 
-			endCh <- struct{}{}
-		}(dc)
+	for moduleDir, dirs := range dirsByModule {
+		for _, platform := range l.build.Platforms {
+			go func(moduleDir string, dirs []string, platform Platform) {
+				l.loadModule(moduleDir, dirs, platform, slotsCh, programsCh, errsCh)
+				endCh <- struct{}{}
+			}(moduleDir, dirs, platform)
+		}
 	}
 
 	// Wait for all programs to be loaded
 	go func() {
-		for i := 0; i < maxResults; i++ {
+		for i := 0; i < numModules; i++ {
 			<-endCh
 		}
 		close(programsCh)
@@ -128,6 +170,158 @@ func (l loader) Programs() (<-chan *goloader.Program, <-chan error, error) {
 	return programsCh, errsCh, nil
 }
 
+// loadModule loads batches one after another in dependency order sharing
+// facts so that facts of imported packages are known when packages importing
+// them are linted. Each batch takes a slot until its programs are picked up.
+func (l loader) loadModule(
+	moduleDir string,
+	dirs []string,
+	platform Platform,
+	slotsCh chan struct{},
+	programsCh chan<- *check.Program,
+	errsCh chan<- error,
+) {
+	dirs, err := l.sortDirsByImports(moduleDir, dirs, platform)
+	if err != nil {
+		errsCh <- err
+		return
+	}
+
+	facts := check.NewFacts()
+
+	for len(dirs) > 0 {
+		batch := dirs
+		if len(batch) > l.concurrency {
+			batch = batch[:l.concurrency]
+		}
+
+		dirs = dirs[len(batch):]
+
+		slotsCh <- struct{}{}
+
+		l.logger.Printf("Loading %d directories from %s for %s\n", len(batch), moduleDir, platform)
+
+		l.loadPrograms(moduleDir, batch, platform, facts, programsCh, errsCh)
+
+		// Release slot only after all programs were picked up
+		<-slotsCh
+	}
+}
+
+// sortDirsByImports orders directories so that directories of imported
+// packages go first (only names and imports of packages are listed)
+func (l loader) sortDirsByImports(moduleDir string, dirs []string, platform Platform) ([]string, error) {
+	conf := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports,
+		Dir:  moduleDir,
+
+		Env:        append(os.Environ(), l.build.Env(platform)...),
+		BuildFlags: l.build.BuildFlags(),
+	}
+
+	pkgs, err := packages.Load(conf, dirs...)
+	if err != nil {
+		return nil, LoadError{moduleDir, err, nil}
+	}
+
+	pkgsByPath := map[string]*packages.Package{}
+	pkgsByDir := map[string]*packages.Package{}
+
+	// Only packages of given directories are listed (without dependencies)
+	for _, pkg := range pkgs {
+		pkgsByPath[pkg.PkgPath] = pkg
+		pkgsByDir[pkg.Dir] = pkg
+	}
+
+	var sortedDirs []string
+
+	visited := map[string]bool{}
+	added := map[string]bool{}
+
+	var visit func(pkg *packages.Package)
+
+	visit = func(pkg *packages.Package) {
+		if visited[pkg.PkgPath] {
+			return
+		}
+
+		visited[pkg.PkgPath] = true
+
+		var importPaths []string
+
+		for importPath := range pkg.Imports {
+			importPaths = append(importPaths, importPath)
+		}
+
+		sort.Strings(importPaths)
+
+		for _, importPath := range importPaths {
+			if imported, found := pkgsByPath[importPath]; found {
+				visit(imported)
+			}
+		}
+
+		if pkg.Dir != "" && !added[pkg.Dir] {
+			added[pkg.Dir] = true
+			sortedDirs = append(sortedDirs, pkg.Dir)
+		}
+	}
+
+	dirs = append([]string{}, dirs...)
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if pkg, found := pkgsByDir[dir]; found {
+			visit(pkg)
+		}
+	}
+
+	// Directories without packages (e.g. for the platform)
+	// are still loaded so that they are reported the same way
+	for _, dir := range dirs {
+		if !added[dir] {
+			sortedDirs = append(sortedDirs, dir)
+		}
+	}
+
+	return sortedDirs, nil
+}
+
+// groupDirsByModule groups non-empty directories by their module root.
+// Directories outside of any module (e.g. in GOPATH) are loaded together.
+func (l loader) groupDirsByModule(pathsByDir map[string]*dirContents) map[string][]string {
+	dirsByModule := map[string][]string{}
+
+	for _, dc := range pathsByDir {
+		if len(dc.Paths) == 0 {
+			l.logger.Printf("Skipping %s with 0 files\n", dc.Path)
+			continue
+		}
+
+		moduleDir := l.moduleDir(dc.Path)
+		dirsByModule[moduleDir] = append(dirsByModule[moduleDir], dc.Path)
+	}
+
+	return dirsByModule
+}
+
+func (l loader) moduleDir(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if isModuleDir(d) {
+			return d
+		}
+
+		if filepath.Dir(d) == d {
+			return l.dir
+		}
+	}
+}
+
+func isModuleDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
 func (l loader) groupPathsByDir(dir string) (map[string]*dirContents, error) {
 	pathsByDir := map[string]*dirContents{}
 
@@ -141,6 +335,23 @@ func (l loader) groupPathsByDir(dir string) (map[string]*dirContents, error) {
 			return nil
 		}
 
+		if l.filter.IsExcluded(path) {
+			l.logger.Printf("Excluding %s\n", path)
+
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// Same as `go list` ... patterns do not match packages of nested modules
+		// (including go.work members) since they are loaded separately
+		if info.IsDir() && isModuleDir(path) {
+			l.logger.Printf("Skipping nested module %s\n", path)
+			return filepath.SkipDir
+		}
+
 		d := filepath.Dir(path)
 		dc, ok := pathsByDir[d]
 		if !ok {
@@ -163,31 +374,233 @@ func (l loader) groupPathsByDir(dir string) (map[string]*dirContents, error) {
 	return pathsByDir, nil
 }
 
-func (l loader) loadProgram(packageName string) (*goloader.Program, error) {
-	conf := goloader.Config{
-		Fset:          token.NewFileSet(),
-		TypeChecker:   gotypes.Config{},
-		ParserMode:    parser.ParseComments,
-		SourceImports: true,
+// resolveDir finds directory for a package import path
+// or a relative/absolute file system path
+func (l loader) resolveDir(arg string) (string, error) {
+	pkg, err := build.Import(arg, l.dir, build.FindOnly)
+	if err != nil {
+		return "", fmt.Errorf("Resolving %s %#v", arg, err)
+	}
+
+	return pkg.Dir, nil
+}
+
+// loadPrograms loads a batch of directories in a single pass;
+// packages that failed to load are returned as errors.
+// Programs are not kept once they are picked up so that
+// they could be garbage collected once they are linted.
+func (l loader) loadPrograms(
+	moduleDir string,
+	dirs []string,
+	platform Platform,
+	facts *check.Facts,
+	programsCh chan<- *check.Program,
+	errsCh chan<- error,
+) {
+	conf := &packages.Config{
+		Mode: loadMode,
+		Dir:  moduleDir,
+		Fset: token.NewFileSet(),
+
+		Env:        append(os.Environ(), l.build.Env(platform)...),
+		BuildFlags: l.build.BuildFlags(),
+
+		// Includes both internal/external _test.go files
+		Tests: true,
 	}
 
-	// ImportWithTests includes both internal/external _test.go files
-	err := conf.ImportWithTests(packageName)
+	// Absolute directory paths are valid package patterns
+	pkgs, err := packages.Load(conf, dirs...)
 	if err != nil {
-		return nil, fmt.Errorf("Importing %s %#v", packageName, err)
+		errsCh <- LoadError{moduleDir, err, nil}
+		return
 	}
 
-	var typeCheckerErrs []error
+	pkgsByDir := map[string][]*packages.Package{}
 
-	// By default this outputs to Stderr
-	conf.TypeChecker.Error = func(err error) {
-		typeCheckerErrs = append(typeCheckerErrs, err)
+	for _, pkg := range pkgs {
+		pkgsByDir[pkg.Dir] = append(pkgsByDir[pkg.Dir], pkg)
 	}
 
-	program, err := conf.Load()
-	if err != nil {
-		return nil, LoadError{packageName, err, typeCheckerErrs}
+	var programs []*check.Program
+
+	// Facts are shared by packages of all batches hence all
+	// packages are added before any of programs are linted
+
+	for _, dir := range dirs {
+		program, err := l.buildProgram(conf.Fset, dir, platform, pkgsByDir[dir])
+		if err != nil {
+			errsCh <- err
+		} else if program != nil {
+			for _, pkg := range program.InitialPackages() {
+				facts.AddPackage(pkg)
+			}
+			programs = append(programs, program)
+		}
+	}
+
+	// Loaded packages (including their syntax and type information)
+	// are only referenced by programs (and facts) from now on
+	for i := range programs {
+		program := programs[i]
+		programs[i] = nil
+		programsCh <- program
+	}
+}
+
+// buildProgram returns nil program if there are no files
+// to be built in the directory for the given platform
+func (l loader) buildProgram(
+	fset *token.FileSet,
+	dir string,
+	platform Platform,
+	pkgs []*packages.Package,
+) (*check.Program, error) {
+	pkgs = l.initialPackages(pkgs)
+	if len(pkgs) == 0 {
+		l.logger.Printf("Skipping %s without files for %s\n", dir, platform)
+		return nil, nil
+	}
+
+	var pkgInfos []*check.PackageInfo
+	var pkgErrs []error
+
+	for _, pkg := range pkgs {
+		// Same errors are shown whether or not packages with errors are allowed
+		errs := l.packageErrors(pkg)
+
+		for _, pkgErr := range errs {
+			pkgErrs = append(pkgErrs, pkgErr)
+		}
+
+		pkgInfo := &check.PackageInfo{
+			Pkg:   pkg.Types,
+			Dir:   dir,
+			Files: l.includedFiles(fset, pkg.Syntax),
+		}
+
+		// Type information might be missing if package failed to load
+		if pkg.TypesInfo != nil {
+			pkgInfo.Info = *pkg.TypesInfo
+		}
+
+		if l.allowErrors {
+			pkgInfo.Errors = errs
+		}
+
+		pkgInfos = append(pkgInfos, pkgInfo)
+	}
+
+	// Package name is only used for error reporting
+	packageName := pkgs[0].PkgPath
+
+	if len(pkgErrs) > 0 && !l.allowErrors {
+		loadErr := fmt.Errorf("couldn't load packages due to errors: %s for %s", packageName, platform)
+		return nil, LoadError{packageName, loadErr, pkgErrs}
+	}
+
+	return check.NewProgram(fset, pkgInfos), nil
+}
+
+// packageErrors converts errors to include parsed positions.
+// Errors from building export data repeat type-checking errors
+// hence they are only kept if there are no type-checking errors.
+func (l loader) packageErrors(pkg *packages.Package) []check.PackageError {
+	var typeErrs, otherErrs []check.PackageError
+
+	for _, pkgErr := range pkg.Errors {
+		err := check.PackageError{
+			Position: parsePosition(pkgErr.Pos),
+			Msg:      pkgErr.Msg,
+		}
+
+		if pkgErr.Kind == packages.ListError {
+			otherErrs = append(otherErrs, err)
+		} else {
+			typeErrs = append(typeErrs, err)
+		}
+	}
+
+	if len(typeErrs) > 0 {
+		return typeErrs
+	}
+
+	return otherErrs
+}
+
+// parsePosition parses "file:line:col" or "file:line" position;
+// invalid position is returned for "-" or empty position
+func parsePosition(pos string) token.Position {
+	var position token.Position
+
+	pieces := strings.Split(pos, ":")
+
+	for i := 0; i < 2 && len(pieces) > 1; i++ {
+		num, err := strconv.Atoi(pieces[len(pieces)-1])
+		if err != nil {
+			break
+		}
+
+		position.Column, position.Line = position.Line, num
+		pieces = pieces[:len(pieces)-1]
+	}
+
+	if position.Line > 0 {
+		position.Filename = strings.Join(pieces, ":")
+	}
+
+	return position
+}
+
+// includedFiles drops excluded files from the package
+// even though they were used for type checking
+func (l loader) includedFiles(fset *token.FileSet, files []*ast.File) []*ast.File {
+	var includedFiles []*ast.File
+
+	for _, file := range files {
+		path := fset.Position(file.Package).Filename
+
+		if l.filter.IsExcluded(path) {
+			l.logger.Printf("Excluding %s\n", path)
+			continue
+		}
+
+		includedFiles = append(includedFiles, file)
+	}
+
+	return includedFiles
+}
+
+// initialPackages drops generated test main packages
+// and packages that are superseded by their test variants
+// (test variant includes both regular and internal _test.go files).
+// Similar to go/loader external test packages are listed first.
+func (l loader) initialPackages(pkgs []*packages.Package) []*packages.Package {
+	var xtestPkgs, result []*packages.Package
+
+	withTestVariant := map[string]bool{}
+
+	for _, pkg := range pkgs {
+		if pkg.ForTest == pkg.PkgPath {
+			withTestVariant[pkg.PkgPath] = true
+		}
+	}
+
+	for _, pkg := range pkgs {
+		switch {
+		case strings.HasSuffix(pkg.PkgPath, ".test"):
+			// e.g. pkg.test
+		case len(pkg.GoFiles) == 0 && len(pkg.IgnoredFiles) > 0:
+			// e.g. all files are excluded by build constraints
+		case pkg.ForTest == "" && withTestVariant[pkg.PkgPath]:
+			// e.g. pkg when pkg [pkg.test] is present
+		case pkg.ForTest != "" && pkg.ForTest != pkg.PkgPath:
+			// e.g. pkg_test [pkg.test]
+			xtestPkgs = append(xtestPkgs, pkg)
+		default:
+			result = append(result, pkg)
+		}
 	}
 
-	return program, nil
+	return append(xtestPkgs, result...)
 }
@@ -0,0 +1,149 @@
+package linter_test
+
+import (
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/linter"
+)
+
+// Each load covers at most -j directories and loads of
+// a module wait for programs of previous loads to be picked up
+func TestLoaderLoadsBatchesOfDirectories(t *testing.T) {
+	var numPrograms []int
+
+	for _, concurrency := range []int{1, 2, 3} {
+		loader := newTestLoader(t, "..", []string{"./testcase/..."}, true, concurrency)
+
+		programsCh, errsCh, err := loader.Programs()
+		if err != nil {
+			t.Fatalf("Programs %v", err)
+		}
+
+		// Programs loaded together share a file set
+		programsByFset := map[*token.FileSet]int{}
+		total := 0
+
+		for program := range programsCh {
+			programsByFset[program.Fset]++
+			total++
+		}
+
+		for err := range errsCh {
+			t.Fatalf("Expected no load errors but was %v", err)
+		}
+
+		for _, num := range programsByFset {
+			if num > concurrency {
+				t.Fatalf("Expected at most %d programs to be loaded together but was %d", concurrency, num)
+			}
+		}
+
+		numPrograms = append(numPrograms, total)
+	}
+
+	if numPrograms[0] < 3 || numPrograms[0] != numPrograms[1] || numPrograms[0] != numPrograms[2] {
+		t.Fatalf("Expected same programs to be loaded in batches of any size but was %v", numPrograms)
+	}
+}
+
+// Imported packages are loaded in earlier batches than packages importing them
+func TestLoaderFactsAreSharedBetweenBatches(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"a/a.go": "package a\n\nimport \"example.com/m/z\"\n\nfunc F() {\n\tz.Close()\n\tz.Fail()\n}\n",
+		"z/z.go": "package z\n\nimport \"errors\"\n\nfunc Close() error { return nil }\n\nfunc Fail() error { return errors.New(\"fail\") }\n",
+	})
+
+	defer os.RemoveAll(dir)
+
+	logger := log.New(ioutil.Discard, "", 0)
+
+	l := linter.NewLinter(discardReporter{}, check.NewDefaultRegistry(), linter.Config{}, check.Selection{}, false, nil, nil, 1, logger)
+
+	programsCh, errsCh, err := newTestLoader(t, dir, []string{"./..."}, false, 1).Programs()
+	if err != nil {
+		t.Fatalf("Programs %v", err)
+	}
+
+	var funcs []string
+
+	// Programs are linted one by one in the order they are loaded
+	for program := range programsCh {
+		problems, _ := l.Run(program)
+
+		for _, problem := range problems {
+			funcs = append(funcs, problem.Context["func"])
+		}
+	}
+
+	for err := range errsCh {
+		t.Fatalf("Expected no load errors but was %v", err)
+	}
+
+	// Close always returns nil error according to facts of z
+	if !reflect.DeepEqual(funcs, []string{"func example.com/m/z.Fail() error"}) {
+		t.Fatalf("Expected only error of Fail to be found but was %#v", funcs)
+	}
+}
+
+func newTestLoader(t *testing.T, dir string, args []string, allowErrors bool, concurrency int) linter.Loader {
+	filter := linter.NewPathFilter(dir, nil, nil)
+	build := linter.BuildConfig{Platforms: []linter.Platform{{}}}
+
+	loader, err := linter.NewLoaderFromArgs(dir, args, filter, build, allowErrors, concurrency, log.New(ioutil.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("NewLoaderFromArgs %v", err)
+	}
+
+	return loader
+}
+
+// Errors from building export data repeat type-checking errors
+// regardless of whether packages with errors are allowed
+func TestLoaderPackageErrorsAreShownOnce(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"main.go": "package broken\n\nvar x int = \"str\"\n",
+	})
+
+	defer os.RemoveAll(dir)
+
+	for _, allowErrors := range []bool{false, true} {
+		loader := newTestLoader(t, dir, nil, allowErrors, 1)
+
+		programsCh, errsCh, err := loader.Programs()
+		if err != nil {
+			t.Fatalf("Programs %v", err)
+		}
+
+		var msgs []string
+
+		for program := range programsCh {
+			for _, pkg := range program.InitialPackages() {
+				for _, pkgErr := range pkg.Errors {
+					msgs = append(msgs, pkgErr.Error())
+				}
+			}
+		}
+
+		for err := range errsCh {
+			loadErr, ok := err.(linter.LoadError)
+			if !ok {
+				t.Fatalf("Expected load error but was %#v", err)
+			}
+
+			for _, pkgErr := range loadErr.UnderlyingErrs() {
+				msgs = append(msgs, pkgErr.Error())
+			}
+		}
+
+		if len(msgs) != 1 || !strings.Contains(msgs[0], "main.go:3:13: cannot use \"str\"") {
+			t.Fatalf("Expected single type error with allowErrors %t but was %#v", allowErrors, msgs)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package linter
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultExcludes matches paths ignored by the go tool:
+// directories and files beginning with "." or "_",
+// testdata directories and vendored dependencies
+var DefaultExcludes = []string{".*", "_*", "testdata", "vendor"}
+
+// PathFilter decides which directories and files are loaded.
+// Patterns are matched against base name and slash-separated path
+// relative to the root they were specified for
+// (e.g. "fixtures", "integration/*", "*_generated.go").
+// Included paths take precedence over excluded paths.
+type PathFilter struct {
+	rules []pathFilterRule
+}
+
+type pathFilterRule struct {
+	root     string
+	includes []string
+	excludes []string
+}
+
+// NewPathFilter returns filter with default excludes
+// and given patterns relative to root directory
+func NewPathFilter(root string, includes, excludes []string) PathFilter {
+	return PathFilter{}.With("", nil, DefaultExcludes).With(root, includes, excludes)
+}
+
+// NewPathFilterFromStrs parses comma-separated lists of patterns
+func NewPathFilterFromStrs(root, includes, excludes string) PathFilter {
+	return NewPathFilter(root, splitPatterns(includes), splitPatterns(excludes))
+}
+
+// With returns filter that also includes patterns relative to another root
+// (e.g. patterns from a configuration file in a parent directory)
+func (f PathFilter) With(root string, includes, excludes []string) PathFilter {
+	rule := pathFilterRule{
+		root:     root,
+		includes: includes,
+		excludes: excludes,
+	}
+
+	return PathFilter{rules: append(append([]pathFilterRule{}, f.rules...), rule)}
+}
+
+func (f PathFilter) IsExcluded(path string) bool {
+	var excluded, included bool
+
+	for _, rule := range f.rules {
+		relPath := rule.relPath(path)
+
+		excluded = excluded || matchGlobs(rule.excludes, relPath)
+		included = included || matchGlobs(rule.includes, relPath)
+	}
+
+	return excluded && !included
+}
+
+func (f PathFilter) validate() error {
+	for _, rule := range f.rules {
+		for _, pattern := range append(append([]string{}, rule.includes...), rule.excludes...) {
+			_, err := path.Match(pattern, "")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// relPath makes path relative to the root if possible
+func (r pathFilterRule) relPath(path string) string {
+	if r.root == "" {
+		return path
+	}
+
+	relPath, err := filepath.Rel(r.root, path)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return path
+	}
+
+	return relPath
+}
+
+func matchGlobs(patterns []string, relPath string) bool {
+	slashPath := filepath.ToSlash(relPath)
+	baseName := path.Base(slashPath)
+
+	for _, pattern := range patterns {
+		// Patterns were validated when loader was constructed
+		if ok, _ := path.Match(pattern, baseName); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitPatterns(str string) []string {
+	var patterns []string
+
+	for _, pattern := range strings.Split(str, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if len(pattern) > 0 {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
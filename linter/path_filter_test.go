@@ -0,0 +1,56 @@
+package linter_test
+
+import (
+	"testing"
+
+	"github.com/cppforlife/lint/linter"
+)
+
+func TestPathFilterIsExcluded(t *testing.T) {
+	// Configuration file is in a parent of the current directory
+	cwdFilter := linter.NewPathFilter("/src/project/cmd", []string{"keep_generated.go"}, []string{"fixtures"})
+	filter := cwdFilter.With("/src/project", []string{"vendor"}, []string{"*_generated.go", "integration/*"})
+
+	examples := []struct {
+		path     string
+		excluded bool
+	}{
+		{"/src/project/cmd/main.go", false},
+
+		// Default excludes match base names
+		{"/src/project/cmd/.git", true},
+		{"/src/project/cmd/_tools", true},
+		{"/src/project/cmd/testdata", true},
+
+		// Include from configuration overrides default exclude
+		{"/src/project/vendor", false},
+
+		// Include from flags overrides exclude from configuration
+		{"/src/project/cmd/foo_generated.go", true},
+		{"/src/project/cmd/keep_generated.go", false},
+
+		// Relative path patterns are relative to their root
+		{"/src/project/integration/suite", true},
+		{"/src/project/cmd/integration/suite", false},
+		{"/src/project/integration/suite/nested", false},
+
+		// Base name patterns match at any depth
+		{"/src/project/cmd/fixtures", true},
+		{"/src/project/cmd/sub/fixtures", true},
+
+		// Paths outside of root only match base name
+		{"/other/fixtures", true},
+		{"/other/integration/suite", false},
+	}
+
+	for _, ex := range examples {
+		if excluded := filter.IsExcluded(ex.path); excluded != ex.excluded {
+			t.Fatalf("Expected %s excluded to be %t", ex.path, ex.excluded)
+		}
+	}
+
+	// With does not modify original filter
+	if cwdFilter.IsExcluded("/src/project/integration/suite") {
+		t.Fatalf("Expected original filter not to include configuration patterns")
+	}
+}
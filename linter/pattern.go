@@ -0,0 +1,126 @@
+package linter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// expandArgs finds directories for all package arguments.
+// Each argument is an import path or a file system path
+// optionally including '...' wildcards (similar to `go list`).
+// Directories matched by multiple arguments are included once.
+func (l loader) expandArgs() (map[string]*dirContents, error) {
+	pathsByDir := map[string]*dirContents{}
+
+	for _, arg := range l.args {
+		argPathsByDir, err := l.expandArg(arg)
+		if err != nil {
+			return pathsByDir, err
+		}
+
+		for dir, dc := range argPathsByDir {
+			if _, found := pathsByDir[dir]; found {
+				l.logger.Printf("Skipping duplicate directory %s from %s\n", dir, arg)
+				continue
+			}
+
+			pathsByDir[dir] = dc
+		}
+	}
+
+	return pathsByDir, nil
+}
+
+func (l loader) expandArg(arg string) (map[string]*dirContents, error) {
+	wildcardIdx := strings.Index(arg, "...")
+
+	if wildcardIdx < 0 {
+		dir, err := l.resolveDir(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.groupPathsInDir(dir)
+	}
+
+	// Walk from the deepest directory without a wildcard
+	// e.g. ./check/... -> ./check, github.com/org/lint... -> github.com/org
+	rootArg := arg[:wildcardIdx]
+	if strings.HasSuffix(rootArg, "/") {
+		rootArg = strings.TrimSuffix(rootArg, "/")
+	} else {
+		rootArg = path.Dir(rootArg)
+	}
+
+	rootDir, err := l.resolveDir(rootArg)
+	if err != nil {
+		return nil, err
+	}
+
+	pathsByDir, err := l.groupPathsByDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	match := matchPattern(arg)
+
+	for dir := range pathsByDir {
+		relDir, err := filepath.Rel(rootDir, dir)
+		if err != nil {
+			return nil, fmt.Errorf("Relativizing directory '%s': %s", dir, err)
+		}
+
+		name := rootArg
+		if relDir != "." {
+			name += "/" + filepath.ToSlash(relDir)
+		}
+
+		if !match(name) {
+			delete(pathsByDir, dir)
+		}
+	}
+
+	return pathsByDir, nil
+}
+
+// groupPathsInDir is a non-recursive version of groupPathsByDir
+func (l loader) groupPathsInDir(dir string) (map[string]*dirContents, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Reading directory '%s': %s", dir, err)
+	}
+
+	dc := &dirContents{Path: dir}
+
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+
+		if info.IsDir() || l.filter.IsExcluded(path) {
+			continue
+		}
+
+		dc.AddPath(path)
+	}
+
+	return map[string]*dirContents{dir: dc}, nil
+}
+
+// matchPattern returns a function that matches names
+// against pattern with '...' wildcards the same way `go list` does:
+// '...' matches any string and 'foo/...' also matches 'foo'
+func matchPattern(pattern string) func(name string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\.\.\.`, `.*`, -1)
+
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+
+	reg := regexp.MustCompile(`^` + re + `$`)
+
+	return reg.MatchString
+}
@@ -0,0 +1,131 @@
+package linter_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestLoaderPatterns(t *testing.T) {
+	root := writeTestModule(t, map[string]string{
+		"foo/foo.go":        "package foo\n",
+		"foo/README.md":     "foo\n",
+		"foo/bar/bar.go":    "package bar\n",
+		"foobar/foobar.go":  "package foobar\n",
+		"x/internal/y/y.go": "package y\n",
+		"x/z/z.go":          "package z\n",
+		"x/z/testdata/t.go": "package t\n",
+		"x/_tools/tools.go": "package tools\n",
+		"nested/go.mod":     "module example.com/nested\n",
+		"nested/nested.go":  "package nested\n",
+		"nested/n/n.go":     "package n\n",
+	})
+
+	defer os.RemoveAll(root)
+
+	examples := []struct {
+		args     []string
+		expected []string
+	}{
+		{
+			[]string{"./foo"},
+			[]string{"example.com/m/foo"},
+		},
+		{
+			// foo/... matches foo itself but not foobar
+			[]string{"./foo/..."},
+			[]string{"example.com/m/foo", "example.com/m/foo/bar"},
+		},
+		{
+			[]string{"./foo..."},
+			[]string{"example.com/m/foo", "example.com/m/foo/bar", "example.com/m/foobar"},
+		},
+		{
+			// Directories without Go files (e.g. x), excluded directories
+			// and nested modules are skipped
+			[]string{"./..."},
+			[]string{"example.com/m/foo", "example.com/m/foo/bar", "example.com/m/foobar", "example.com/m/x/internal/y", "example.com/m/x/z"},
+		},
+		{
+			// Nested modules are only loaded when given explicitly
+			[]string{"./nested/..."},
+			[]string{"example.com/nested", "example.com/nested/n"},
+		},
+		{
+			[]string{"./x/.../y"},
+			[]string{"example.com/m/x/internal/y"},
+		},
+		{
+			[]string{"./foo", "./x/z"},
+			[]string{"example.com/m/foo", "example.com/m/x/z"},
+		},
+		{
+			// Directories matched by multiple arguments are loaded once
+			[]string{"./foo/...", "./foo/bar", "./foo"},
+			[]string{"example.com/m/foo", "example.com/m/foo/bar"},
+		},
+	}
+
+	for _, ex := range examples {
+		pkgPaths := loadTestPackagePaths(t, root, ex.args)
+
+		if !reflect.DeepEqual(pkgPaths, ex.expected) {
+			t.Fatalf("Expected %v to load %v but was %v", ex.args, ex.expected, pkgPaths)
+		}
+	}
+}
+
+// writeTestModule writes files into a temporary module example.com/m
+func writeTestModule(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "lint-module")
+	if err != nil {
+		t.Fatalf("TempDir %v", err)
+	}
+
+	files["go.mod"] = "module example.com/m\n"
+
+	for path, content := range files {
+		path = filepath.Join(root, filepath.FromSlash(path))
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatalf("MkdirAll %v", err)
+		}
+
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("WriteFile %v", err)
+		}
+	}
+
+	return root
+}
+
+// loadTestPackagePaths returns sorted paths of packages in all loaded programs
+func loadTestPackagePaths(t *testing.T, dir string, args []string) []string {
+	loader := newTestLoader(t, dir, args, false, 1)
+
+	programsCh, errsCh, err := loader.Programs()
+	if err != nil {
+		t.Fatalf("Programs %v", err)
+	}
+
+	var pkgPaths []string
+
+	for program := range programsCh {
+		for _, pkg := range program.InitialPackages() {
+			pkgPaths = append(pkgPaths, pkg.Pkg.Path())
+		}
+	}
+
+	for err := range errsCh {
+		t.Fatalf("Expected no load errors but was %v", err)
+	}
+
+	sort.Strings(pkgPaths)
+
+	return pkgPaths
+}
@@ -0,0 +1,189 @@
+package linter
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// problemSet keeps track of reported packages and problems
+// so that a package or a file loaded in multiple programs
+// (e.g. once for each platform) is reported only once
+type problemSet struct {
+	pkgs map[string]*types.Package
+
+	// Same problem might be legitimately found multiple times
+	// in a single program (e.g. multiple error return values)
+	problemCounts map[string]int
+
+	// Problems suppressed by comments or matched
+	// by baseline entries are only counted
+	suppressed int
+	baselined  int
+
+	// Suppression comments seen in any program
+	// and those that suppressed at least one problem
+	ignores     map[string]ignoreDirective
+	usedIgnores map[string]struct{}
+
+	lock sync.Mutex
+}
+
+func newProblemSet() *problemSet {
+	return &problemSet{
+		pkgs:          map[string]*types.Package{},
+		problemCounts: map[string]int{},
+		ignores:       map[string]ignoreDirective{},
+		usedIgnores:   map[string]struct{}{},
+	}
+}
+
+// AddPackage returns true if package was not seen before
+func (s *problemSet) AddPackage(pkg *types.Package) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.pkgs[pkg.Path()]; found {
+		return false
+	}
+
+	s.pkgs[pkg.Path()] = pkg
+
+	return true
+}
+
+// AddProblems returns problems found in a single program
+// that were not already found in previously added programs
+func (s *problemSet) AddProblems(problems []check.Problem) []check.Problem {
+	var newProblems []check.Problem
+
+	counts := map[string]int{}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, problem := range problems {
+		key := s.problemKey(problem)
+		counts[key]++
+
+		if counts[key] > s.problemCounts[key] {
+			newProblems = append(newProblems, problem)
+		}
+	}
+
+	for key, count := range counts {
+		if count > s.problemCounts[key] {
+			s.problemCounts[key] = count
+		}
+	}
+
+	return newProblems
+}
+
+// AddSuppressed records directives that suppressed problems (one per problem)
+func (s *problemSet) AddSuppressed(used ignoreDirectives) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.suppressed += len(used)
+
+	for _, d := range used {
+		s.usedIgnores[d.Key()] = struct{}{}
+	}
+}
+
+func (s *problemSet) AddBaselined(count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.baselined += count
+}
+
+// Packages returns all added packages by their path
+func (s *problemSet) Packages() map[string]*types.Package {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pkgs := map[string]*types.Package{}
+
+	for path, pkg := range s.pkgs {
+		pkgs[path] = pkg
+	}
+
+	return pkgs
+}
+
+// AddIgnores records directives that could have suppressed problems
+func (s *problemSet) AddIgnores(directives ignoreDirectives) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, d := range directives {
+		if _, found := s.ignores[d.Key()]; !found {
+			s.ignores[d.Key()] = d
+		}
+	}
+}
+
+// UnusedIgnores returns directives that did not suppress
+// any problems in any of the added programs
+func (s *problemSet) UnusedIgnores() ignoreDirectives {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var unused ignoreDirectives
+
+	for key, d := range s.ignores {
+		if _, found := s.usedIgnores[key]; !found {
+			unused = append(unused, d)
+		}
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].Key() < unused[j].Key()
+	})
+
+	return unused
+}
+
+func (s *problemSet) Summary() Summary {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	summary := Summary{
+		Packages:   len(s.pkgs),
+		Suppressed: s.suppressed,
+		Baselined:  s.baselined,
+	}
+
+	for _, count := range s.problemCounts {
+		summary.Problems += count
+	}
+
+	// Suppressed and baselined problems were added before being filtered out
+	summary.Problems -= s.suppressed + s.baselined
+
+	return summary
+}
+
+func (s *problemSet) problemKey(problem check.Problem) string {
+	pieces := []string{problem.Position.String(), problem.CheckID, problem.Text}
+
+	for _, name := range problem.Context.Names() {
+		pieces = append(pieces, name+"="+problem.Context[name])
+	}
+
+	for _, diff := range problem.Diffs {
+		pieces = append(pieces, fmt.Sprintf("%s:%s->%s", diff.NameStr(), diff.CurrentStr(), diff.DesiredStr()))
+	}
+
+	for _, fix := range problem.Fixes {
+		pieces = append(pieces, fmt.Sprintf("%s:%s->%s", fix.NameStr(), fix.CurrentStr(), fix.DesiredStr()))
+	}
+
+	return strings.Join(pieces, "\n")
+}
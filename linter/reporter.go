@@ -2,14 +2,35 @@ package linter
 
 import (
 	"go/ast"
-
-	gotypes "code.google.com/p/go.tools/go/types"
+	"go/types"
 
 	"github.com/cppforlife/lint/check"
 )
 
 type Reporter interface {
-	ReportPackage(*gotypes.Package)
-	ReportFile(*gotypes.Package, *ast.File)
+	ReportPackage(*types.Package)
+
+	// ReportFile includes path of the file and
+	// checks that ran against it (without ones skipped)
+	ReportFile(pkg *types.Package, file *ast.File, path string, checkIDs []string)
+
 	ReportProblem(check.Problem)
+	ReportSummary(Summary)
+}
+
+// FormatReporter presents results in one of output formats;
+// Flush is called once after everything was reported and
+// returns an error if results could not be presented
+type FormatReporter interface {
+	Reporter
+	UI
+	Flush() error
+}
+
+// Summary is reported once all programs are linted
+type Summary struct {
+	Packages   int `json:"packages"`
+	Problems   int `json:"problems"`
+	Suppressed int `json:"suppressed"`
+	Baselined  int `json:"baselined"`
 }
@@ -0,0 +1,403 @@
+package linter
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/types"
+	"io"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// Relative artifact locations are resolved against this base
+	sarifSrcRoot = "SRCROOT"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds"`
+	Invocations        []sarifInvocation                `json:"invocations"`
+	Results            []sarifResult                    `json:"results"`
+	Properties         map[string]interface{}           `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                     `json:"name"`
+	InformationURI string                     `json:"informationUri"`
+	Rules          []sarifReportingDescriptor `json:"rules"`
+}
+
+type sarifReportingDescriptor struct {
+	ID                   string               `json:"id"`
+	ShortDescription     sarifMessage         `json:"shortDescription"`
+	DefaultConfiguration sarifReportingConfig `json:"defaultConfiguration"`
+}
+
+type sarifReportingConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Fixes      []sarifFix             `json:"fixes,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+
+	// Used for replacements
+	ByteOffset *int `json:"byteOffset,omitempty"`
+	ByteLength *int `json:"byteLength,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifContent `json:"insertedContent,omitempty"`
+}
+
+type sarifContent struct {
+	Text string `json:"text"`
+}
+
+// sarifReporter writes a SARIF 2.1.0 log with a single run on Flush;
+// each registered check is a rule and each problem is a result
+type sarifReporter struct {
+	writer io.Writer
+
+	// Artifact locations are relative to this directory
+	dir string
+
+	// Rules are determined on Flush so that checks
+	// registered after reporter was created are included
+	registry *check.Registry
+
+	results       []sarifResult
+	notifications []sarifNotification
+	summary       *Summary
+	lock          sync.Mutex
+
+	logger *log.Logger
+}
+
+func NewSARIFReporter(writer io.Writer, dir string, registry *check.Registry, logger *log.Logger) *sarifReporter {
+	return &sarifReporter{
+		writer:   writer,
+		dir:      dir,
+		registry: registry,
+		results:  []sarifResult{},
+		logger:   logger,
+	}
+}
+
+func (r *sarifReporter) ReportPackage(pkg *types.Package) {}
+
+func (r *sarifReporter) ReportFile(*types.Package, *ast.File, string, []string) {}
+
+func (r *sarifReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	location := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: r.artifactLocation(problem.Position.Filename),
+		},
+	}
+
+	if problem.Position.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   problem.Position.Line,
+			StartColumn: problem.Position.Column,
+		}
+	}
+
+	result := sarifResult{
+		RuleID:    problem.CheckID,
+		Level:     sarifLevel(problem.Severity),
+		Message:   sarifMessage{problem.Text},
+		Locations: []sarifLocation{location},
+	}
+
+	properties := map[string]interface{}{}
+
+	if problem.Package != nil {
+		properties["package"] = problem.Package.Path()
+	}
+
+	if len(problem.Context) > 0 {
+		properties["context"] = problem.Context
+	}
+
+	var diffs []JSONDiff
+
+	for _, diff := range problem.Diffs {
+		diffs = append(diffs, newJSONDiff(diff))
+	}
+
+	if len(diffs) > 0 {
+		properties["diffs"] = diffs
+	}
+
+	result.Properties = properties
+
+	for _, f := range problem.Fixes {
+		if sarifFix, ok := r.fix(f); ok {
+			result.Fixes = append(result.Fixes, sarifFix)
+		}
+	}
+
+	r.results = append(r.results, result)
+}
+
+func (r *sarifReporter) ReportSummary(summary Summary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.summary = &summary
+}
+
+func (r *sarifReporter) DisplayError(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if presentableErr, ok := err.(PresentableError); ok {
+		if !presentableErr.IsPresentable() {
+			return
+		}
+	}
+
+	texts := []string{err.Error()}
+
+	if errWithUnderlyingErrs, ok := err.(ErrorWithUnderlyingErrors); ok {
+		for _, underlyingErr := range errWithUnderlyingErrs.UnderlyingErrs() {
+			texts = append(texts, "- "+underlyingErr.Error())
+		}
+	}
+
+	r.notifications = append(r.notifications, sarifNotification{
+		Level:   "error",
+		Message: sarifMessage{strings.Join(texts, "\n")},
+	})
+}
+
+func (r *sarifReporter) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	rules, ruleIndexes := r.rules()
+
+	for i, result := range r.results {
+		ruleIndex, found := ruleIndexes[result.RuleID]
+		if !found {
+			ruleIndex = -1 // unknown rule
+		}
+		r.results[i].RuleIndex = ruleIndex
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "lint",
+				InformationURI: "https://github.com/cppforlife/lint",
+				Rules:          rules,
+			},
+		},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			sarifSrcRoot: {URI: fileURI(r.dir) + "/"},
+		},
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful:        len(r.notifications) == 0,
+			ToolExecutionNotifications: append([]sarifNotification{}, r.notifications...),
+		}},
+		Results: r.results,
+	}
+
+	if r.summary != nil {
+		run.Properties = map[string]interface{}{"summary": r.summary}
+	}
+
+	bytes, err := json.MarshalIndent(sarifLog{sarifVersion, sarifSchema, []sarifRun{run}}, "", "  ")
+	if err != nil {
+		r.logger.Printf("Failed to marshal SARIF: %#v", err)
+		return nil
+	}
+
+	_, err = r.writer.Write(append(bytes, '\n'))
+	if err != nil {
+		r.logger.Printf("Failed to write SARIF: %#v", err)
+	}
+
+	return nil
+}
+
+func (r *sarifReporter) rules() ([]sarifReportingDescriptor, map[string]int) {
+	var rules []sarifReportingDescriptor
+
+	indexes := map[string]int{}
+
+	for _, def := range definitions(r.registry) {
+		indexes[def.ID] = len(rules)
+		rules = append(rules, sarifReportingDescriptor{
+			ID:                   def.ID,
+			ShortDescription:     sarifMessage{def.Description},
+			DefaultConfiguration: sarifReportingConfig{Level: sarifLevel(def.Severity)},
+		})
+	}
+
+	return rules, indexes
+}
+
+// fix is only presented if it could describe its changes
+// (e.g. file renames cannot be presented)
+func (r *sarifReporter) fix(f fix.Fix) (sarifFix, bool) {
+	editsFix, ok := f.(fix.EditsFix)
+	if !ok {
+		return sarifFix{}, false
+	}
+
+	// Edits of applied fixes were determined before fixing
+	edits, err := editsFix.Edits()
+	if err != nil {
+		r.logger.Printf("Failed to describe fix '%s': %s\n", f.NameStr(), err)
+		return sarifFix{}, false
+	}
+
+	if len(edits) == 0 {
+		r.logger.Printf("Fix '%s' does not change any files\n", f.NameStr())
+		return sarifFix{}, false
+	}
+
+	changesByFile := map[string]*sarifArtifactChange{}
+	var files []string
+
+	for _, edit := range edits {
+		change, found := changesByFile[edit.File]
+		if !found {
+			change = &sarifArtifactChange{ArtifactLocation: r.artifactLocation(edit.File)}
+			changesByFile[edit.File] = change
+			files = append(files, edit.File)
+		}
+
+		offset, length := edit.Offset, edit.End-edit.Offset
+
+		replacement := sarifReplacement{
+			DeletedRegion: sarifRegion{ByteOffset: &offset, ByteLength: &length},
+		}
+
+		if len(edit.NewText) > 0 {
+			replacement.InsertedContent = &sarifContent{edit.NewText}
+		}
+
+		change.Replacements = append(change.Replacements, replacement)
+	}
+
+	sort.Strings(files)
+
+	result := sarifFix{Description: sarifMessage{r.fixDescription(f)}}
+
+	for _, file := range files {
+		result.ArtifactChanges = append(result.ArtifactChanges, *changesByFile[file])
+	}
+
+	return result, true
+}
+
+func (r *sarifReporter) fixDescription(diff fix.Diff) string {
+	current := diff.CurrentStr()
+	if !diff.HasCurrent() {
+		current = "(missing)"
+	}
+	return diff.NameStr() + ": " + current + " -> " + diff.DesiredStr()
+}
+
+// artifactLocation is relative to the reporter directory if possible
+func (r *sarifReporter) artifactLocation(path string) sarifArtifactLocation {
+	relPath, err := filepath.Rel(r.dir, path)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return sarifArtifactLocation{URI: fileURI(path)}
+	}
+
+	return sarifArtifactLocation{
+		URI:       (&url.URL{Path: filepath.ToSlash(relPath)}).EscapedPath(),
+		URIBaseID: sarifSrcRoot,
+	}
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func sarifLevel(severity check.Severity) string {
+	switch severity {
+	case check.SeverityError:
+		return "error"
+	case check.SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
@@ -0,0 +1,230 @@
+package linter_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"go/types"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+	"github.com/cppforlife/lint/linter"
+)
+
+//go:embed testdata/sarif-schema-2.1.0.json
+var sarifSchemaJSON []byte
+
+func TestSARIFReporter(t *testing.T) {
+	var buf bytes.Buffer
+
+	reporter := linter.NewSARIFReporter(&buf, "/src", newTestRegistry(t, reporterTestDefs...), log.New(&buf, "", 0))
+
+	pkg := types.NewPackage("github.com/org/pkg", "pkg")
+
+	reporter.ReportPackage(pkg)
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "packageDirName",
+		Severity: check.SeverityWarning,
+		Text:     "Package name should match directory name",
+		Package:  pkg,
+		Position: token.Position{Filename: "/src/pkg/main.go", Line: 1, Column: 1},
+		Context:  check.Context{"dirName": "pkg"},
+		Fixes: []fix.Fix{
+			fix.NewTextEdits(fix.SimpleDiff{Name: "package", Current: "other", Desired: "pkg"}, []fix.TextEdit{
+				{File: "/src/pkg/main.go", Offset: 8, End: 13, NewText: "pkg"},
+				{File: "/src/pkg/a b.go", Offset: 8, End: 14}, // deletion
+			}),
+		},
+	})
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "plugin", // not registered
+		Severity: check.SeverityInfo,
+		Text:     "Plugin problem outside of the directory",
+		Position: token.Position{Filename: "/other/main.go"},
+		Diffs:    []fix.Diff{fix.SimpleDiff{Name: "doc", Desired: "// Package other", MissingCurrent: true}},
+	})
+	reporter.DisplayError(errorWithCauses{"Failed to load github.com/org/broken", []error{errors.New("main.go:1:1: oops")}})
+	reporter.DisplayError(linter.FoundProblemsError{}) // not presentable
+	reporter.ReportSummary(linter.Summary{Packages: 1, Problems: 2})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	validateSARIF(t, buf.Bytes())
+
+	// Registered checks are followed by checks of the linter itself
+	expected := `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [{
+    "tool": {"driver": {
+      "name": "lint",
+      "informationUri": "https://github.com/cppforlife/lint",
+      "rules": [
+        {"id": "packageDirName", "shortDescription": {"text": "Package name should match directory name"}, "defaultConfiguration": {"level": "warning"}},
+        {"id": "errorAssignment", "shortDescription": {"text": "Errors should be assigned and used"}, "defaultConfiguration": {"level": "error"}},
+        {"id": "packageErrors", "shortDescription": {"text": "Packages should load without errors"}, "defaultConfiguration": {"level": "error"}},
+        {"id": "ignoreDirective", "shortDescription": {"text": "Suppression comments should be valid and used"}, "defaultConfiguration": {"level": "warning"}},
+        {"id": "baseline", "shortDescription": {"text": "Baseline entries should match problems"}, "defaultConfiguration": {"level": "warning"}}
+      ]
+    }},
+    "originalUriBaseIds": {"SRCROOT": {"uri": "file:///src/"}},
+    "invocations": [{
+      "executionSuccessful": false,
+      "toolExecutionNotifications": [
+        {"level": "error", "message": {"text": "Failed to load github.com/org/broken\n- main.go:1:1: oops"}}
+      ]
+    }],
+    "results": [
+      {
+        "ruleId": "packageDirName",
+        "ruleIndex": 0,
+        "level": "warning",
+        "message": {"text": "Package name should match directory name"},
+        "locations": [{"physicalLocation": {
+          "artifactLocation": {"uri": "pkg/main.go", "uriBaseId": "SRCROOT"},
+          "region": {"startLine": 1, "startColumn": 1}
+        }}],
+        "fixes": [{
+          "description": {"text": "package: other -> pkg"},
+          "artifactChanges": [
+            {
+              "artifactLocation": {"uri": "pkg/a%20b.go", "uriBaseId": "SRCROOT"},
+              "replacements": [{"deletedRegion": {"byteOffset": 8, "byteLength": 6}}]
+            },
+            {
+              "artifactLocation": {"uri": "pkg/main.go", "uriBaseId": "SRCROOT"},
+              "replacements": [{"deletedRegion": {"byteOffset": 8, "byteLength": 5}, "insertedContent": {"text": "pkg"}}]
+            }
+          ]
+        }],
+        "properties": {"package": "github.com/org/pkg", "context": {"dirName": "pkg"}}
+      },
+      {
+        "ruleId": "plugin",
+        "ruleIndex": -1,
+        "level": "note",
+        "message": {"text": "Plugin problem outside of the directory"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "file:///other/main.go"}}}],
+        "properties": {"diffs": [{"name": "doc", "current": "", "desired": "// Package other", "missingCurrent": true}]}
+      }
+    ],
+    "properties": {"summary": {"packages": 1, "problems": 2, "suppressed": 0, "baselined": 0}}
+  }]
+}`
+
+	assertJSONEqual(t, expected, buf.Bytes())
+}
+
+type failingEditsFix struct {
+	fix.SimpleDiff
+}
+
+func (failingEditsFix) Fix() error { return nil }
+
+func (failingEditsFix) Edits() ([]fix.TextEdit, error) { return nil, errors.New("no edits") }
+
+func TestSARIFReporterFixesWithoutEdits(t *testing.T) {
+	var buf, logBuf bytes.Buffer
+
+	reporter := linter.NewSARIFReporter(&buf, "/src", newTestRegistry(t, reporterTestDefs...), log.New(&logBuf, "", 0))
+
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "packageDirName",
+		Severity: check.SeverityWarning,
+		Text:     "Package name should match directory name",
+		Position: token.Position{Filename: "/src/pkg/main.go", Line: 1, Column: 1},
+		Fixes: []fix.Fix{
+			failingEditsFix{fix.SimpleDiff{Name: "package", Current: "other", Desired: "pkg"}},
+			fix.NewTextEdits(fix.SimpleDiff{Name: "doc", Desired: "// Package pkg", MissingCurrent: true}, nil),
+		},
+	})
+
+	err := reporter.Flush()
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	validateSARIF(t, buf.Bytes())
+
+	var result struct {
+		Runs []struct {
+			Results []struct {
+				Fixes []interface{} `json:"fixes"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+
+	err = json.Unmarshal(buf.Bytes(), &result)
+	if err != nil {
+		t.Fatalf("Unmarshal %v", err)
+	}
+
+	if fixes := result.Runs[0].Results[0].Fixes; len(fixes) != 0 {
+		t.Fatalf("Expected no fixes but was %#v", fixes)
+	}
+
+	// Each fix that cannot be presented is logged once
+	expected := "Failed to describe fix 'package': no edits\nFix 'doc' does not change any files\n"
+
+	if logBuf.String() != expected {
+		t.Fatalf("Expected log %q but was %q", expected, logBuf.String())
+	}
+}
+
+func validateSARIF(t *testing.T, output []byte) {
+	schemaDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(sarifSchemaJSON))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON schema %v", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+
+	err = compiler.AddResource("sarif-schema-2.1.0.json", schemaDoc)
+	if err != nil {
+		t.Fatalf("AddResource %v", err)
+	}
+
+	schema, err := compiler.Compile("sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatalf("Compile %v", err)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(output))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON %v\n%s", err, output)
+	}
+
+	err = schema.Validate(doc)
+	if err != nil {
+		t.Fatalf("Validate %v\n%s", err, output)
+	}
+}
+
+// assertJSONEqual compares documents regardless of formatting and key order
+func assertJSONEqual(t *testing.T, expected string, actual []byte) {
+	var expectedVal, actualVal interface{}
+
+	err := json.Unmarshal([]byte(expected), &expectedVal)
+	if err != nil {
+		t.Fatalf("Unmarshal expected %v", err)
+	}
+
+	err = json.Unmarshal(actual, &actualVal)
+	if err != nil {
+		t.Fatalf("Unmarshal %v\n%s", err, actual)
+	}
+
+	if !reflect.DeepEqual(actualVal, expectedVal) {
+		t.Fatalf("Expected %s but was %s", expected, actual)
+	}
+}
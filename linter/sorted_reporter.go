@@ -0,0 +1,131 @@
+package linter
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"sync"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// SortedReporter keeps reported packages, problems and errors
+// until Flush so that they are presented in a stable order
+// regardless of the order in which programs were linted
+type SortedReporter struct {
+	reporter Reporter
+	ui       UI
+
+	pkgs     map[string]*types.Package
+	problems []check.Problem
+	errs     []error
+	summary  *Summary
+
+	lock sync.Mutex
+}
+
+func NewSortedReporter(reporter Reporter, ui UI) *SortedReporter {
+	return &SortedReporter{
+		reporter: reporter,
+		ui:       ui,
+		pkgs:     map[string]*types.Package{},
+	}
+}
+
+func (r *SortedReporter) ReportPackage(pkg *types.Package) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.pkgs[pkg.Path()] = pkg
+}
+
+func (r *SortedReporter) ReportFile(pkg *types.Package, file *ast.File, path string, checkIDs []string) {
+	r.reporter.ReportFile(pkg, file, path, checkIDs)
+}
+
+func (r *SortedReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.problems = append(r.problems, problem)
+}
+
+func (r *SortedReporter) ReportSummary(summary Summary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.summary = &summary
+}
+
+func (r *SortedReporter) DisplayError(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+// Flush reports packages ordered by their path, each followed by its problems
+// ordered by file, line and column; errors and summary are displayed last
+func (r *SortedReporter) Flush() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var pkgPaths []string
+
+	for path := range r.pkgs {
+		pkgPaths = append(pkgPaths, path)
+	}
+
+	sort.Strings(pkgPaths)
+
+	problemsByPkg := map[string][]check.Problem{}
+
+	for _, problem := range r.problems {
+		path := problem.Package.Path()
+		problemsByPkg[path] = append(problemsByPkg[path], problem)
+	}
+
+	for _, path := range pkgPaths {
+		r.reporter.ReportPackage(r.pkgs[path])
+
+		problems := problemsByPkg[path]
+
+		// Stable sort keeps order of problems found at the same position
+		sort.SliceStable(problems, func(i, j int) bool {
+			return lessPosition(problems[i], problems[j])
+		})
+
+		for _, problem := range problems {
+			r.reporter.ReportProblem(problem)
+		}
+	}
+
+	sort.SliceStable(r.errs, func(i, j int) bool {
+		return r.errs[i].Error() < r.errs[j].Error()
+	})
+
+	for _, err := range r.errs {
+		r.ui.DisplayError(err)
+	}
+
+	if r.summary != nil {
+		r.reporter.ReportSummary(*r.summary)
+	}
+
+	r.pkgs = map[string]*types.Package{}
+	r.problems = nil
+	r.errs = nil
+	r.summary = nil
+}
+
+func lessPosition(a, b check.Problem) bool {
+	if a.Position.Filename != b.Position.Filename {
+		return a.Position.Filename < b.Position.Filename
+	}
+
+	if a.Position.Line != b.Position.Line {
+		return a.Position.Line < b.Position.Line
+	}
+
+	return a.Position.Column < b.Position.Column
+}
@@ -0,0 +1,248 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/cppforlife/lint/check"
+)
+
+// TemplateCheck describes check that found a problem
+type TemplateCheck struct {
+	ID               string         `json:"id"`
+	Description      string         `json:"description"`
+	Severity         check.Severity `json:"severity"` // default severity
+	EnabledByDefault bool           `json:"enabledByDefault"`
+}
+
+// TemplateProblem is rendered by a per problem template
+type TemplateProblem struct {
+	JSONProblem
+	Check TemplateCheck `json:"check"`
+}
+
+// TemplateResult is rendered once by a result template
+type TemplateResult struct {
+	Packages []JSONPackage     `json:"packages"`
+	Problems []TemplateProblem `json:"problems"`
+	Errors   []JSONError       `json:"errors"`
+	Summary  *Summary          `json:"summary,omitempty"`
+	Checks   []TemplateCheck   `json:"checks"`
+}
+
+// ParseFormatTemplate parses template with helper functions:
+// relpath (relative to dir), json and join (separator goes first;
+// elements of any slice are formatted with fmt.Sprint)
+func ParseFormatTemplate(name, text, dir string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"relpath": func(path string) string {
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return path
+			}
+			return relPath
+		},
+		"json": func(value interface{}) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+		"join": func(sep string, elems interface{}) (string, error) {
+			value := reflect.ValueOf(elems)
+			if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+				return "", fmt.Errorf("Expected slice to join but was %T", elems)
+			}
+
+			var strs []string
+
+			for i := 0; i < value.Len(); i++ {
+				strs = append(strs, fmt.Sprint(value.Index(i).Interface()))
+			}
+
+			return strings.Join(strs, sep), nil
+		},
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Parsing format template: %s", err)
+	}
+
+	return tmpl, nil
+}
+
+// templateReporter renders template for each problem as soon as it is
+// reported (errors are displayed by ui) or once on Flush for all results;
+// rendering errors are returned by Flush
+type templateReporter struct {
+	writer     io.Writer
+	tmpl       *template.Template
+	perProblem bool
+	ui         UI
+
+	// Checks are determined once first problem is reported
+	// so that checks registered after reporter was created are included
+	registry *check.Registry
+	checks   map[string]TemplateCheck
+
+	result TemplateResult
+	lock   sync.Mutex
+
+	// First error of rendering a problem
+	renderErr error
+
+	logger *log.Logger
+}
+
+func NewTemplateReporter(
+	writer io.Writer,
+	tmpl *template.Template,
+	perProblem bool,
+	ui UI,
+	registry *check.Registry,
+	logger *log.Logger,
+) *templateReporter {
+	return &templateReporter{
+		writer:     writer,
+		tmpl:       tmpl,
+		perProblem: perProblem,
+		ui:         ui,
+		registry:   registry,
+		result: TemplateResult{
+			Packages: []JSONPackage{},
+			Problems: []TemplateProblem{},
+			Errors:   []JSONError{},
+			Checks:   []TemplateCheck{},
+		},
+		logger: logger,
+	}
+}
+
+func (r *templateReporter) ReportPackage(pkg *types.Package) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.result.Packages = append(r.result.Packages, JSONPackage{Path: pkg.Path(), Name: pkg.Name()})
+}
+
+func (r *templateReporter) ReportFile(*types.Package, *ast.File, string, []string) {}
+
+func (r *templateReporter) ReportProblem(problem check.Problem) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	templateProblem := TemplateProblem{
+		JSONProblem: NewJSONProblem(problem),
+		Check:       r.check(problem.CheckID),
+	}
+
+	if r.perProblem {
+		err := r.render(templateProblem)
+		if err != nil && r.renderErr == nil {
+			r.renderErr = err
+		}
+	} else {
+		r.result.Problems = append(r.result.Problems, templateProblem)
+	}
+}
+
+func (r *templateReporter) ReportSummary(summary Summary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.result.Summary = &summary
+}
+
+func (r *templateReporter) DisplayError(err error) {
+	if r.perProblem {
+		r.ui.DisplayError(err)
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if presentableErr, ok := err.(PresentableError); ok {
+		if !presentableErr.IsPresentable() {
+			return
+		}
+	}
+
+	r.result.Errors = append(r.result.Errors, NewJSONError(err))
+}
+
+// Flush renders all results (nothing for per problem
+// template since problems are already rendered)
+func (r *templateReporter) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.perProblem {
+		return r.renderErr
+	}
+
+	r.loadChecks()
+
+	for _, def := range definitions(r.registry) {
+		r.result.Checks = append(r.result.Checks, r.checks[def.ID])
+	}
+
+	return r.render(r.result)
+}
+
+// render adds a trailing new line unless template ends with one
+func (r *templateReporter) render(data interface{}) error {
+	var buf bytes.Buffer
+
+	err := r.tmpl.Execute(&buf, data)
+	if err != nil {
+		return fmt.Errorf("Rendering format template: %s", err)
+	}
+
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	_, err = r.writer.Write(buf.Bytes())
+	if err != nil {
+		r.logger.Printf("Failed to write template: %#v", err)
+	}
+
+	return nil
+}
+
+func (r *templateReporter) check(id string) TemplateCheck {
+	r.loadChecks()
+
+	if templateCheck, found := r.checks[id]; found {
+		return templateCheck
+	}
+
+	return TemplateCheck{ID: id} // unknown check
+}
+
+func (r *templateReporter) loadChecks() {
+	if r.checks != nil {
+		return
+	}
+
+	r.checks = map[string]TemplateCheck{}
+
+	for _, def := range definitions(r.registry) {
+		r.checks[def.ID] = TemplateCheck{
+			ID:               def.ID,
+			Description:      def.Description,
+			Severity:         def.Severity,
+			EnabledByDefault: def.EnabledByDefault,
+		}
+	}
+}
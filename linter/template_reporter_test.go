@@ -0,0 +1,143 @@
+package linter_test
+
+import (
+	"bytes"
+	"errors"
+	"go/token"
+	"go/types"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/cppforlife/lint/check"
+	"github.com/cppforlife/lint/check/fix"
+	"github.com/cppforlife/lint/linter"
+)
+
+func reportTemplateResults(reporter linter.FormatReporter) error {
+	pkg := types.NewPackage("github.com/org/pkg", "pkg")
+
+	reporter.ReportPackage(pkg)
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "packageDirName",
+		Severity: check.SeverityError, // configured severity
+		Text:     "Package name should match directory name",
+		Package:  pkg,
+		Position: token.Position{Filename: "/src/pkg/main.go", Line: 1, Column: 1},
+		Context:  check.Context{"dirName": "pkg"},
+		Fixes: []fix.Fix{
+			fix.NewTextEdits(fix.SimpleDiff{Name: "package", Current: "other", Desired: "pkg"}, nil),
+			fix.NewTextEdits(fix.SimpleDiff{Name: "doc", Desired: "// Package pkg", MissingCurrent: true}, nil),
+		},
+	})
+	reporter.ReportProblem(check.Problem{
+		CheckID:  "plugin", // not registered
+		Severity: check.SeverityInfo,
+		Text:     "Plugin problem",
+		Package:  pkg,
+		Position: token.Position{Filename: "/src/pkg/a/b.go", Line: 3, Column: 2},
+	})
+	reporter.DisplayError(errorWithCauses{"Failed to load github.com/org/broken", []error{errors.New("main.go:1:1: oops")}})
+	reporter.DisplayError(linter.FoundProblemsError{}) // not presentable
+	reporter.ReportSummary(linter.Summary{Packages: 1, Problems: 2, Baselined: 1})
+
+	return reporter.Flush()
+}
+
+func TestTemplateReporterPerProblem(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+
+	text := `{{relpath .File}}:{{.Line}} {{.CheckID}} {{.Severity}} ({{.Check.Description}}, default {{.Check.Severity}}) ` +
+		`{{json .Context}} fixes={{join "; " .Fixes}}`
+
+	tmpl, err := linter.ParseFormatTemplate("test", text, "/src")
+	if err != nil {
+		t.Fatalf("ParseFormatTemplate %v", err)
+	}
+
+	ui := linter.NewPlainUI(&errBuf, log.New(&errBuf, "", 0))
+
+	err = reportTemplateResults(linter.NewTemplateReporter(&buf, tmpl, true, ui, newTestRegistry(t, reporterTestDefs...), log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	// Each problem is rendered on its own line; unknown checks only have an ID
+	// (and no description or default severity)
+	expected := "pkg/main.go:1 packageDirName error (Package name should match directory name, default warning) " +
+		`{"dirName":"pkg"} fixes=package: other -> pkg; doc: (missing) -> // Package pkg` + "\n" +
+		"pkg/a/b.go:3 plugin info (, default ) {} fixes=\n"
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %q but was %q", expected, buf.String())
+	}
+
+	// Errors are not rendered
+	expectedErrs := "\n[error] Failed to load github.com/org/broken\n        - main.go:1:1: oops\n"
+
+	if errBuf.String() != expectedErrs {
+		t.Fatalf("Expected errors %q but was %q", expectedErrs, errBuf.String())
+	}
+}
+
+func TestTemplateReporterResult(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+
+	text := `{{range .Packages}}{{.Path}}{{end}} problems={{len .Problems}}` +
+		`{{range .Problems}} {{.Check.ID}}/{{.CheckID}}{{end}}` +
+		`{{range .Errors}} error={{.Message}} causes={{len .Errors}}{{end}}` +
+		` summary={{json .Summary}}` +
+		`{{range .Checks}} check={{.ID}}:{{.Severity}}:{{.EnabledByDefault}}{{end}}` + "\n"
+
+	tmpl, err := linter.ParseFormatTemplate("test", text, "/src")
+	if err != nil {
+		t.Fatalf("ParseFormatTemplate %v", err)
+	}
+
+	ui := linter.NewPlainUI(&errBuf, log.New(&errBuf, "", 0))
+
+	err = reportTemplateResults(linter.NewTemplateReporter(&buf, tmpl, false, ui, newTestRegistry(t, reporterTestDefs...), log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("Flush %v", err)
+	}
+
+	// Template is rendered once (without adding another new line);
+	// registered checks are followed by checks of the linter itself
+	expected := "github.com/org/pkg problems=2 packageDirName/packageDirName plugin/plugin" +
+		" error=Failed to load github.com/org/broken causes=1" +
+		` summary={"packages":1,"problems":2,"suppressed":0,"baselined":1}` +
+		" check=packageDirName:warning:true check=errorAssignment:error:false" +
+		" check=packageErrors:error:false check=ignoreDirective:warning:false check=baseline:warning:false\n"
+
+	if buf.String() != expected {
+		t.Fatalf("Expected %q but was %q", expected, buf.String())
+	}
+
+	if errBuf.Len() > 0 {
+		t.Fatalf("Expected errors to be rendered instead of displayed but was %q", errBuf.String())
+	}
+}
+
+func TestTemplateReporterRenderingError(t *testing.T) {
+	for _, perProblem := range []bool{true, false} {
+		var buf bytes.Buffer
+
+		tmpl, err := linter.ParseFormatTemplate("test", `{{.Unknown}}`, "/src")
+		if err != nil {
+			t.Fatalf("ParseFormatTemplate %v", err)
+		}
+
+		ui := linter.NewPlainUI(&buf, log.New(&buf, "", 0))
+
+		err = reportTemplateResults(linter.NewTemplateReporter(&buf, tmpl, perProblem, ui, newTestRegistry(t, reporterTestDefs...), log.New(&buf, "", 0)))
+
+		// Linting fails with an internal error regardless of found problems
+		if err == nil || linter.ExitCode(err, check.SeverityError) != linter.ExitCodeInternal {
+			t.Fatalf("Expected internal error when rendering per problem is %t but was %v", perProblem, err)
+		}
+
+		if !strings.HasPrefix(err.Error(), "Rendering format template: ") {
+			t.Fatalf("Expected rendering error but was '%s'", err.Error())
+		}
+	}
+}
@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"io"
 	"log"
 	"path/filepath"
+	"strings"
 	"sync"
 
-	gotypes "code.google.com/p/go.tools/go/types"
-
 	"github.com/cppforlife/lint/check"
 	"github.com/cppforlife/lint/check/fix"
 )
@@ -36,13 +36,14 @@ const (
 	plainUIFile
 	plainUIProblem
 	plainUIError
+	plainUISummary
 )
 
 type plainUI struct {
 	writer    *bufio.Writer
 	printLock sync.Mutex
 
-	lastPackage  *gotypes.Package
+	lastPackage  *types.Package
 	lastPosition token.Position
 
 	lastMsg plainUIMsg
@@ -57,7 +58,7 @@ func NewPlainUI(writer io.Writer, logger *log.Logger) *plainUI {
 	}
 }
 
-func (ui *plainUI) ReportPackage(pkg *gotypes.Package) {
+func (ui *plainUI) ReportPackage(pkg *types.Package) {
 	ui.printLock.Lock()
 	defer ui.printLock.Unlock()
 
@@ -67,7 +68,7 @@ func (ui *plainUI) ReportPackage(pkg *gotypes.Package) {
 	defer ui.flush()
 }
 
-func (ui plainUI) ReportFile(pkg *gotypes.Package, file *ast.File) {}
+func (ui *plainUI) ReportFile(*types.Package, *ast.File, string, []string) {}
 
 func (ui *plainUI) ReportProblem(problem check.Problem) {
 	ui.printLock.Lock()
@@ -87,15 +88,17 @@ func (ui *plainUI) ReportProblem(problem check.Problem) {
 	}
 
 	ui.write(
-		"%s:%d:%d %s\n",
+		"%s:%d:%d [%s] %s: %s\n",
 		filepath.Base(problem.Position.Filename),
 		problem.Position.Line,
 		problem.Position.Column,
+		problem.CheckID,
+		problem.Severity,
 		problem.Text,
 	)
 
-	for name, value := range problem.Context {
-		ui.write("\t%s = %s\n", name, value)
+	for _, name := range problem.Context.Names() {
+		ui.write("\t%s = %s\n", name, problem.Context[name])
 	}
 
 	for _, diff := range problem.Diffs {
@@ -112,6 +115,37 @@ func (ui *plainUI) ReportProblem(problem check.Problem) {
 	defer ui.flush()
 }
 
+func (ui *plainUI) ReportSummary(summary Summary) {
+	ui.printLock.Lock()
+	defer ui.printLock.Unlock()
+
+	ui.writeLnAfterLastMsg(plainUISummary)
+
+	ui.write(
+		"%s found in %s",
+		pluralize(summary.Problems, "problem"),
+		pluralize(summary.Packages, "package"),
+	)
+
+	var notes []string
+
+	if summary.Suppressed > 0 {
+		notes = append(notes, fmt.Sprintf("%d suppressed", summary.Suppressed))
+	}
+
+	if summary.Baselined > 0 {
+		notes = append(notes, fmt.Sprintf("%d in baseline", summary.Baselined))
+	}
+
+	if len(notes) > 0 {
+		ui.write(" (%s)", strings.Join(notes, ", "))
+	}
+
+	ui.write("\n")
+
+	defer ui.flush()
+}
+
 func (ui *plainUI) displayDiff(diff fix.Diff) {
 	current := diff.CurrentStr()
 	if !diff.HasCurrent() {
@@ -145,6 +179,9 @@ func (ui *plainUI) DisplayError(err error) {
 	defer ui.flush()
 }
 
+// Flush does nothing since output is written as soon as it is reported
+func (ui *plainUI) Flush() error { return nil }
+
 func (ui *plainUI) writeLnAfterLastMsg(currentMsg plainUIMsg) plainUIMsg {
 	lm := ui.lastMsg
 	if lm != currentMsg {
@@ -156,16 +193,23 @@ func (ui *plainUI) writeLnAfterLastMsg(currentMsg plainUIMsg) plainUIMsg {
 	return lm
 }
 
-func (ui plainUI) write(format string, args ...interface{}) {
+func (ui *plainUI) write(format string, args ...interface{}) {
 	_, err := fmt.Fprintf(ui.writer, format, args...)
 	if err != nil {
 		ui.logger.Printf("Failed to print UI: %#v", err)
 	}
 }
 
-func (ui plainUI) flush() {
+func (ui *plainUI) flush() {
 	err := ui.writer.Flush()
 	if err != nil {
 		ui.logger.Printf("Failed to flush UI: %#v", err)
 	}
 }
+
+func pluralize(count int, noun string) string {
+	if count != 1 {
+		noun += "s"
+	}
+	return fmt.Sprintf("%d %s", count, noun)
+}
@@ -0,0 +1,27 @@
+package baseline
+
+import (
+	"errors"
+)
+
+func returnsError() error {
+	return errors.New("desc")
+}
+
+type legacy struct{}
+
+// Problems in baseline are not reported regardless of their position
+func (l *legacy) Close() {
+	returnsError()
+	returnsError()
+}
+
+// Only one of the problems is in baseline
+func legacyFunc() {
+	returnsError()
+	returnsError()
+}
+
+func newFunc() {
+	returnsError()
+}
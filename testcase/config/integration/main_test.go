@@ -0,0 +1,15 @@
+// Test package suffix is not enforced for integration tests by configuration
+package integration
+
+import (
+	"errors"
+)
+
+// Severity is lowered for integration tests by configuration
+func testSeverity() {
+	testSe()
+}
+
+func testSe() error {
+	return errors.New("desc")
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+func testIgnoredFuncs() {
+	// Ignored by configuration
+	fmt.Printf("hello")
+	fmt.Println("hello")
+
+	// Not ignored
+	testSe()
+}
+
+func testSe() error {
+	return errors.New("desc")
+}
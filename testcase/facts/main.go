@@ -0,0 +1,40 @@
+package facts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+type Closer struct{}
+
+// Close satisfies io.Closer but never fails
+func (Closer) Close() error { return nil }
+
+func CloseAll(closers ...Closer) error {
+	for _, c := range closers {
+		c.Close()
+	}
+	return closeNothing()
+}
+
+func closeNothing() error { return nil }
+
+// Fatalf wraps os.Exit
+func Fatalf(msg string, args ...interface{}) error {
+	fmt.Fprintf(os.Stderr, msg, args...)
+	os.Exit(1)
+	panic("unreachable")
+}
+
+func Must(err error) error {
+	if err != nil {
+		Fatalf("failed: %s", err)
+	}
+	return errors.New("must")
+}
+
+func useHelpers() {
+	CloseAll()
+	Must(nil)
+}
@@ -0,0 +1,12 @@
+package facts_test
+
+import (
+	"github.com/cppforlife/lint/testcase/facts"
+)
+
+func useHelpers() {
+	facts.Closer{}.Close()
+	facts.CloseAll(facts.Closer{})
+	facts.Fatalf("exiting")
+	facts.Must(nil)
+}
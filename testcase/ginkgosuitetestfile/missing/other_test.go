@@ -0,0 +1,8 @@
+// Missing suite test file is reported once per directory
+package missing_test
+
+import (
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("Another test inside missing package", func() {})
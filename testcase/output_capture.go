@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/cppforlife/lint/check"
 	"github.com/cppforlife/lint/linter"
 )
 
@@ -19,18 +21,57 @@ func CaptureLintOutput(t *testing.T, goPath, packageName string) ([]byte, error)
 
 	logger := log.New(logDevice, "[debug] ", 0)
 
-	loader, err := linter.NewLoaderFromArgs(goPath, []string{packageName}, logger)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd %v", err)
+	}
+
+	filter := linter.NewPathFilter(wd, nil, nil)
+
+	build := linter.BuildConfig{Platforms: []linter.Platform{{}}}
+
+	loader, err := linter.NewLoaderFromArgs(wd, []string{packageName}, filter, build, true, 1, logger)
 	if err != nil {
 		t.Fatalf("NewLoaderFromArgs %v", err)
 	}
 
 	ui := linter.NewPlainUI(buf, logger)
 
-	l := linter.NewLinter(ui, logger)
+	reporter := linter.NewSortedReporter(ui, ui)
 
-	cli := linter.NewCLI(ui, loader, l, logger)
+	// Test cases might include configuration files
+	config, err := linter.FindConfig(filepath.Join(goPath, "src", packageName))
+	if err != nil {
+		t.Fatalf("FindConfig %v", err)
+	}
+
+	registry := check.NewDefaultRegistry()
+
+	err = config.RegisterPlugins(registry)
+	if err != nil {
+		t.Fatalf("RegisterPlugins %v", err)
+	}
+
+	var baseline *linter.Baseline
+
+	// Test cases might include baseline files
+	baselinePath := filepath.Join(goPath, "src", packageName, "baseline.json")
+
+	if _, err := os.Stat(baselinePath); err == nil {
+		baseline, err = linter.LoadBaseline(baselinePath)
+		if err != nil {
+			t.Fatalf("LoadBaseline %v", err)
+		}
+	}
+
+	// Unused suppression comments are reported as well
+	l := linter.NewLinter(reporter, registry, config, check.Selection{}, true, baseline, nil, 2, logger)
+
+	cli := linter.NewCLI(reporter, loader, l, 1, logger)
 
 	err = cli.Run(false)
+
+	reporter.Flush()
 	if err != nil {
 		if _, ok := err.(linter.FoundProblemsError); !ok {
 			t.Fatalf("Run %v", err)
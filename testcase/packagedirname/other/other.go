@@ -0,0 +1,2 @@
+// Package name is fixed in all files with a single problem
+package pkg
@@ -0,0 +1,11 @@
+package plugin
+
+// TODO handle negative values
+func abs(v int) int {
+	return v
+}
+
+// TODO(#2) already refers to an issue
+func sum(a, b int) int {
+	return a + b // TODO overflow
+}
@@ -0,0 +1,4 @@
+package plugin_test
+
+// TODO cover negative values
+func example() {}
@@ -0,0 +1,27 @@
+#!/bin/sh
+
+# Reports TODO comments without an issue in files from the request
+# and suggests a fix referring to the issue from check options
+req=$(cat)
+
+issue=$(printf '%s' "$req" | sed -n 's/.*"issue":"\([^"]*\)".*/\1/p')
+files=$(printf '%s' "$req" | sed -n 's/.*"dir":"[^"]*","files":\[\([^]]*\)\].*/\1/p' | tr -d '"' | tr ',' ' ')
+
+if [ -z "$files" ]; then
+  echo '{"problems": []}'
+  exit 0
+fi
+
+awk -v issue="$issue" '
+BEGIN { printf "{\"problems\": [" }
+FNR == 1 { offset = 0 }
+match($0, /TODO[^(]/) {
+  printf "%s{\"file\": \"%s\", \"line\": %d, \"column\": %d,", sep, FILENAME, FNR, RSTART
+  printf " \"text\": \"TODO comment should refer to an issue\",", ""
+  printf " \"fixes\": [{\"name\": \"todo\", \"current\": \"TODO\", \"desired\": \"TODO(%s)\",", issue
+  printf " \"edits\": [{\"file\": \"%s\", \"offset\": %d, \"end\": %d, \"newText\": \"TODO(%s)\"}]}]}", FILENAME, offset + RSTART - 1, offset + RSTART + 3, issue
+  sep = ","
+}
+{ offset += length($0) + 1 }
+END { print "]}" }
+' $files
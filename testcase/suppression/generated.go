@@ -0,0 +1,7 @@
+//lint:file-ignore errorAssignment generated code
+
+package suppression
+
+func generated() {
+	returnsError()
+}
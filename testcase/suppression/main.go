@@ -0,0 +1,50 @@
+package suppression
+
+import (
+	"errors"
+)
+
+func returnsError() error {
+	return errors.New("desc")
+}
+
+//lint:ignore errorAssignment errors do not matter in this function
+func ignoredFunc() {
+	returnsError()
+
+	if true {
+		returnsError()
+	}
+}
+
+func ignoredStmts() {
+	//lint:ignore errorAssignment only the following statement
+	returnsError()
+	returnsError()
+
+	returnsError() //lint:ignore errorAssignment only this line
+
+	//lint:ignore errorAssignment whole block
+	for i := 0; i < 2; i++ {
+		returnsError()
+	}
+}
+
+func invalidDirectives() {
+	//lint:ignore errorAssignment
+	returnsError()
+
+	//lint:ignore unknownCheck is not a check
+	returnsError()
+}
+
+func unusedDirectives() {
+	//lint:ignore errorAssignment nothing to suppress
+	err := returnsError()
+	if err != nil {
+		panic(err)
+	}
+
+	//lint:ignore packageErrors,errorAssignment nothing to suppress either
+	panic(returnsError())
+}
@@ -0,0 +1,18 @@
+// Package with type errors is still checked by checks
+// that do not need complete type information
+package typeerrs
+
+import (
+	"errors"
+)
+
+func returnsError() error {
+	return errors.New("desc")
+}
+
+func typeError() int {
+	// Not reported since types are incomplete
+	returnsError()
+
+	return "desc"
+}